@@ -0,0 +1,346 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/maruel/devpostdash/devpost"
+	"github.com/maruel/genai/base"
+	"gopkg.in/yaml.v3"
+)
+
+// config is the configuration of the server, read from the JSON or YAML file
+// passed with -config. The file is YAML if its extension is .yaml or .yml.
+// Missing fields keep their value from defaultConfig. Durations are strings
+// like "90s" or "1h". For example:
+//
+//	{
+//	  "host": ":8080",
+//	  "devpost": {"qps": 1, "freshness": "1h", "auto_refresh": "5m"},
+//	  "llm": {"provider": "cerebras", "qps": 0.5},
+//	  "events": {"vibe-coding-hackathon": {"preload": true, "auto_refresh": "1m"}},
+//	  "groups": {"global": {"title": "Global Hack", "events": ["emea", "apac"]}}
+//	}
+//
+// or in YAML:
+//
+//	host: ":8080"
+//	devpost:
+//	  qps: 1
+//	  freshness: 1h
+//	events:
+//	  vibe-coding-hackathon:
+//	    preload: true
+//
+// Every field that is not a map can be overridden with the environment
+// variable DEVPOSTDASH_ followed by the path of the field in upper case, e.g.
+// DEVPOSTDASH_DEVPOST_AUTO_REFRESH=2m. The command line flags override both.
+//
+// The file is read again on SIGHUP. The refresh settings, the events and the
+// groups are applied without losing the cached data; the other settings
+// require a restart.
+type config struct {
+	// Host is the address to listen on.
+	Host string `json:"host"`
+	// CacheDir is where the cache is persisted. Defaults to
+	// ~/.cache/devpostdash.
+	CacheDir string `json:"cache_dir"`
+	// Store is the cache storage: "file" (JSON file) or "log" (append-only
+	// key-value log).
+	Store   string        `json:"store"`
+	Devpost devpostConfig `json:"devpost"`
+	LLM     llmConfig     `json:"llm"`
+	// Events overrides the settings of specific events, by event ID.
+	Events map[string]eventConfig `json:"events"`
+	// Groups are the named event groups shown at /group/<name>.
+	Groups map[string]eventGroup `json:"groups"`
+}
+
+// devpostConfig configures the scraping of devpost.com.
+type devpostConfig struct {
+	// QPS is the maximum number of requests per second to devpost.com.
+	QPS float64 `json:"qps"`
+	// Concurrency is the number of background refreshes run concurrently.
+	Concurrency int `json:"concurrency"`
+	// Freshness is how long the cached data is served before being refreshed
+	// on request. It must be longer than AutoRefresh.
+	Freshness duration `json:"freshness"`
+	// AutoRefresh is the interval of the background refreshes.
+	AutoRefresh duration `json:"auto_refresh"`
+	// StopRefresh is how long after their last request the events are still
+	// refreshed in the background.
+	StopRefresh duration `json:"stop_refresh"`
+	// Referer is sent with the requests.
+	Referer string `json:"referer"`
+}
+
+// llmConfig configures the LLM writing the roasts.
+type llmConfig struct {
+	// Provider is the LLM provider. Empty disables the roasts.
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+	// QPS is the maximum number of requests per second to the provider.
+	QPS float64 `json:"qps"`
+}
+
+// eventConfig overrides the settings of one event.
+type eventConfig struct {
+	// Preload fetches the event on start and keeps it refreshed even when
+	// nobody looks at it.
+	Preload bool `json:"preload"`
+	// AutoRefresh overrides devpost.auto_refresh if set. It must be shorter
+	// than devpost.freshness.
+	AutoRefresh duration `json:"auto_refresh"`
+}
+
+// duration is a time.Duration written as a string like "5m".
+type duration time.Duration
+
+func (d duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *duration) UnmarshalText(b []byte) error {
+	v, err := time.ParseDuration(string(b))
+	*d = duration(v)
+	return err
+}
+
+func defaultConfig() *config {
+	return &config{
+		Host:  ":8080",
+		Store: "file",
+		Devpost: devpostConfig{
+			QPS:         1,
+			Concurrency: 2,
+			Freshness:   duration(time.Hour),
+			AutoRefresh: duration(5 * time.Minute),
+			StopRefresh: duration(4 * time.Hour),
+			Referer:     "https://devpost.com/",
+		},
+		LLM: llmConfig{Provider: "cerebras", Model: base.PreferredGood, QPS: 0.5},
+	}
+}
+
+// loadConfig returns the configuration from the defaults, the file at path if
+// not empty, the environment variables and finally override.
+func loadConfig(path string, lookupEnv func(string) (string, bool), override func(c *config) error) (*config, error) {
+	c := defaultConfig()
+	if path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if ext := filepath.Ext(path); ext == ".yaml" || ext == ".yml" {
+			if b, err = yamlToJSON(b); err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+			}
+		}
+		d := json.NewDecoder(bytes.NewReader(b))
+		d.DisallowUnknownFields()
+		if err := d.Decode(c); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	}
+	if err := applyEnv(reflect.ValueOf(c).Elem(), "DEVPOSTDASH", lookupEnv); err != nil {
+		return nil, err
+	}
+	if override != nil {
+		if err := override(c); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// yamlToJSON converts a YAML document to JSON, so both formats share the JSON
+// field names and decoding.
+func yamlToJSON(b []byte) ([]byte, error) {
+	var v any
+	if err := yaml.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(v)
+}
+
+// applyEnv overrides the fields of the struct v with the environment variables
+// named prefix, "_" and the JSON name of the field in upper case. Nested
+// structs are walked and maps are skipped.
+func applyEnv(v reflect.Value, prefix string, lookupEnv func(string) (string, bool)) error {
+	t := v.Type()
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		key := prefix + "_" + strings.ToUpper(name)
+		f := v.Field(i)
+		switch f.Kind() {
+		case reflect.Struct:
+			if err := applyEnv(f, key, lookupEnv); err != nil {
+				return err
+			}
+			continue
+		case reflect.Map:
+			continue
+		}
+		s, ok := lookupEnv(key)
+		if !ok {
+			continue
+		}
+		var err error
+		switch p := f.Addr().Interface().(type) {
+		case encoding.TextUnmarshaler:
+			err = p.UnmarshalText([]byte(s))
+		case *string:
+			*p = s
+		default:
+			err = json.Unmarshal([]byte(s), p)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func (c *config) validate() error {
+	if c.Devpost.QPS <= 0 {
+		return errors.New("devpost.qps must be positive")
+	}
+	if c.Devpost.Concurrency <= 0 {
+		return errors.New("devpost.concurrency must be positive")
+	}
+	if c.Devpost.Freshness <= c.Devpost.AutoRefresh {
+		return errors.New("devpost.freshness must be longer than devpost.auto_refresh")
+	}
+	if c.LLM.QPS <= 0 {
+		return errors.New("llm.qps must be positive")
+	}
+	for id, e := range c.Events {
+		if !reEventID.MatchString(id) {
+			return fmt.Errorf("invalid event %q", id)
+		}
+		if e.AutoRefresh != 0 && c.Devpost.Freshness <= e.AutoRefresh {
+			return fmt.Errorf("devpost.freshness must be longer than events.%s.auto_refresh", id)
+		}
+	}
+	return checkGroups(c.Groups)
+}
+
+// cacheSettings returns the settings of the cached devpost client.
+func (c *config) cacheSettings() devpost.CacheSettings {
+	s := devpost.CacheSettings{
+		Freshness:   time.Duration(c.Devpost.Freshness),
+		AutoRefresh: time.Duration(c.Devpost.AutoRefresh),
+		StopRefresh: time.Duration(c.Devpost.StopRefresh),
+		Events:      make(map[string]devpost.EventSettings, len(c.Events)),
+	}
+	for id, e := range c.Events {
+		s.Events[id] = devpost.EventSettings{AutoRefresh: time.Duration(e.AutoRefresh), Pinned: e.Preload}
+	}
+	return s
+}
+
+// restartNeeded returns the settings that differ in next but can't be changed
+// while running.
+func (c *config) restartNeeded(next *config) []string {
+	var out []string
+	if c.Host != next.Host {
+		out = append(out, "host")
+	}
+	if c.CacheDir != next.CacheDir {
+		out = append(out, "cache_dir")
+	}
+	if c.Store != next.Store {
+		out = append(out, "store")
+	}
+	if c.Devpost.QPS != next.Devpost.QPS {
+		out = append(out, "devpost.qps")
+	}
+	if c.Devpost.Concurrency != next.Devpost.Concurrency {
+		out = append(out, "devpost.concurrency")
+	}
+	if c.Devpost.Referer != next.Devpost.Referer {
+		out = append(out, "devpost.referer")
+	}
+	if c.LLM != next.LLM {
+		out = append(out, "llm")
+	}
+	return out
+}
+
+// reload returns the configuration running after next is reloaded: the
+// settings applied without a restart are taken from next, the others are kept.
+func (c *config) reload(next *config) *config {
+	out := *c
+	out.Devpost.Freshness = next.Devpost.Freshness
+	out.Devpost.AutoRefresh = next.Devpost.AutoRefresh
+	out.Devpost.StopRefresh = next.Devpost.StopRefresh
+	out.Events = next.Events
+	out.Groups = next.Groups
+	return &out
+}
+
+// preload fetches the events marked to be preloaded so they are cached before
+// they are requested.
+func preload(ctx context.Context, d devpost.Client, c *config) {
+	for _, id := range slices.Sorted(maps.Keys(c.Events)) {
+		if !c.Events[id].Preload {
+			continue
+		}
+		if _, err := d.FetchEvent(ctx, id); err != nil {
+			slog.WarnContext(ctx, "devpostdash", "msg", "failed to preload event", "eventID", id, "err", err)
+			continue
+		}
+		if _, err := d.FetchProjects(ctx, id); err != nil {
+			slog.WarnContext(ctx, "devpostdash", "msg", "failed to preload projects", "eventID", id, "err", err)
+		}
+	}
+}
+
+// reloadOnHangup calls reload whenever the process receives SIGHUP, until ctx
+// is canceled.
+func reloadOnHangup(ctx context.Context, reload func() error) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(ch)
+		for {
+			select {
+			case <-ch:
+				if err := reload(); err != nil {
+					slog.ErrorContext(ctx, "devpostdash", "msg", "failed to reload the configuration", "err", err)
+				} else {
+					slog.InfoContext(ctx, "devpostdash", "msg", "reloaded the configuration")
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
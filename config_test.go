@@ -0,0 +1,148 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	p := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestLoadConfig(t *testing.T) {
+	p := writeConfig(t, `{
+		"host": ":9090",
+		"devpost": {"freshness": "2h", "auto_refresh": "10m"},
+		"llm": {"provider": ""},
+		"events": {"vibe": {"preload": true, "auto_refresh": "1m"}},
+		"groups": {"global": {"events": ["emea", "apac"]}}
+	}`)
+	env := map[string]string{"DEVPOSTDASH_DEVPOST_QPS": "2.5", "DEVPOSTDASH_DEVPOST_STOP_REFRESH": "24h", "DEVPOSTDASH_LLM_MODEL": "tiny"}
+	lookupEnv := func(k string) (string, bool) {
+		v, ok := env[k]
+		return v, ok
+	}
+	cfg, err := loadConfig(p, lookupEnv, func(c *config) error {
+		c.Host = ":1234"
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != ":1234" || cfg.Store != "file" || cfg.LLM.Provider != "" || cfg.LLM.Model != "tiny" || cfg.LLM.QPS != 0.5 {
+		t.Errorf("Unexpected config %+v", cfg)
+	}
+	if d := cfg.Devpost; d.QPS != 2.5 || d.Concurrency != 2 || time.Duration(d.Freshness) != 2*time.Hour || time.Duration(d.StopRefresh) != 24*time.Hour {
+		t.Errorf("Unexpected devpost config %+v", d)
+	}
+	s := cfg.cacheSettings()
+	if s.AutoRefresh != 10*time.Minute || s.Events["vibe"].AutoRefresh != time.Minute || !s.Events["vibe"].Pinned {
+		t.Errorf("Unexpected settings %+v", s)
+	}
+	if g := cfg.Groups["global"]; len(g.Events) != 2 {
+		t.Errorf("Unexpected groups %+v", cfg.Groups)
+	}
+	want := []string{"host", "devpost.qps", "llm"}
+	if got := defaultConfig().restartNeeded(cfg); !slices.Equal(got, want) {
+		t.Errorf("restartNeeded() = %v, want %v", got, want)
+	}
+	// Only the refresh settings, the events and the groups are reloaded, so
+	// the other changes are still pending after a reload.
+	running := defaultConfig().reload(cfg)
+	if got := running.restartNeeded(cfg); !slices.Equal(got, want) {
+		t.Errorf("restartNeeded() = %v, want %v", got, want)
+	}
+	if got := running.restartNeeded(defaultConfig()); len(got) != 0 {
+		t.Errorf("restartNeeded() = %v, want none", got)
+	}
+	if running.Devpost.AutoRefresh != cfg.Devpost.AutoRefresh || running.Devpost.QPS != defaultConfig().Devpost.QPS || len(running.Events) != 1 {
+		t.Errorf("Unexpected running config %+v", running)
+	}
+
+	// Without a file.
+	if cfg, err := loadConfig("", lookupEnv, nil); err != nil || cfg.Host != ":8080" || cfg.Devpost.QPS != 2.5 {
+		t.Errorf("loadConfig() = %+v, %v", cfg, err)
+	}
+}
+
+func TestLoadConfigYAML(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "config.yaml")
+	content := `
+host: ":9090"
+devpost:
+  freshness: 2h
+  auto_refresh: 10m
+events:
+  vibe:
+    preload: true
+    auto_refresh: 1m
+groups:
+  global:
+    events: [emea, apac]
+`
+	if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	noEnv := func(string) (string, bool) { return "", false }
+	cfg, err := loadConfig(p, noEnv, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != ":9090" || time.Duration(cfg.Devpost.Freshness) != 2*time.Hour || cfg.Devpost.QPS != 1 {
+		t.Errorf("Unexpected config %+v", cfg)
+	}
+	if e := cfg.Events["vibe"]; !e.Preload || time.Duration(e.AutoRefresh) != time.Minute {
+		t.Errorf("Unexpected events %+v", cfg.Events)
+	}
+	if g := cfg.Groups["global"]; !slices.Equal(g.Events, []string{"emea", "apac"}) {
+		t.Errorf("Unexpected groups %+v", cfg.Groups)
+	}
+
+	for _, content := range []string{"hots: \":8080\"\n", "devpost: [1]\n", "devpost:\n  freshness: soon\n", "host: [\n"} {
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadConfig(p, noEnv, nil); err == nil {
+			t.Errorf("%q: expected an error", content)
+		}
+	}
+	// An empty file keeps the defaults.
+	if err := os.WriteFile(p, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err := loadConfig(p, noEnv, nil); err != nil || cfg.Host != ":8080" {
+		t.Errorf("loadConfig() = %+v, %v", cfg, err)
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	noEnv := func(string) (string, bool) { return "", false }
+	for _, content := range []string{
+		`{"hots": ":8080"}`,
+		`{"devpost": {"freshness": "soon"}}`,
+		`{"devpost": {"freshness": "1m", "auto_refresh": "5m"}}`,
+		`{"devpost": {"qps": 0}}`,
+		`{"events": {"a/b": {}}}`,
+		`{"events": {"vibe": {"auto_refresh": "2h"}}}`,
+		`{"groups": {"g": {"events": ["a.example.com"]}}}`,
+	} {
+		if _, err := loadConfig(writeConfig(t, content), noEnv, nil); err == nil {
+			t.Errorf("%s: expected an error", content)
+		}
+	}
+	badEnv := func(k string) (string, bool) { return "fast", k == "DEVPOSTDASH_DEVPOST_QPS" }
+	if _, err := loadConfig("", badEnv, nil); err == nil {
+		t.Error("expected an error")
+	}
+}
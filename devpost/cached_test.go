@@ -0,0 +1,618 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devpost
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClient returns new values on every fetch.
+type fakeClient struct {
+	n atomic.Int64
+}
+
+func (f *fakeClient) Close() error { return nil }
+
+func (f *fakeClient) FetchEvent(ctx context.Context, eventID string) (*EventInfo, error) {
+	return &EventInfo{ID: eventID, Title: "Event " + strconv.FormatInt(f.n.Add(1), 10)}, nil
+}
+
+func (f *fakeClient) FetchPrizes(ctx context.Context, eventID string) ([]Prize, error) {
+	return []Prize{{Name: "Grand", Quantity: int(f.n.Add(1))}}, nil
+}
+
+func (f *fakeClient) FetchProjects(ctx context.Context, eventID string) ([]*Project, error) {
+	n := int(f.n.Add(1))
+	var out []*Project
+	for i := range 10 {
+		id := strconv.Itoa(i)
+		out = append(out, &Project{
+			ID:    id,
+			Title: "Project " + id,
+			URL:   "https://devpost.com/software/" + id,
+			Likes: n + i,
+			Team:  []Person{{Name: "Alice", URL: "https://devpost.com/alice"}},
+		})
+	}
+	return out, nil
+}
+
+func (f *fakeClient) FetchProject(ctx context.Context, p *Project) (*Project, error) {
+	p2 := *p
+	p2.Description = "Description " + strconv.FormatInt(f.n.Add(1), 10)
+	p2.Tags = []string{"go"}
+	p2.Team = []Person{{Name: "Alice", URL: "https://devpost.com/alice", Role: "Everything"}}
+	p2.LastRefresh = time.Now()
+	return &p2, nil
+}
+
+func (f *fakeClient) Upstream(ctx context.Context) (*UpstreamStatus, error) {
+	return &UpstreamStatus{State: BreakerClosed}, nil
+}
+
+func (f *fakeClient) FetchPerson(ctx context.Context, username string) (*Participant, error) {
+	return nil, &HTTPError{StatusCode: http.StatusNotFound}
+}
+
+func (f *fakeClient) ListEvents(ctx context.Context, q EventQuery) ([]Hackathon, error) {
+	return nil, nil
+}
+
+// TestCachedClientConcurrent hammers the cached client concurrently. It is
+// meant to be run with -race.
+func TestCachedClientConcurrent(t *testing.T) {
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "store.json"), time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	// Refresh continuously in the background.
+	d, err := NewCached(t.Context(), &fakeClient{}, time.Hour, time.Millisecond, store, SchedulerOptions{Concurrency: 4, QPS: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := d.(*cachedClient)
+	if _, err := c.FetchEvent(t.Context(), "e"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 200*time.Millisecond)
+	defer cancel()
+	var wg sync.WaitGroup
+	hammer := func(f func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				// The calls waiting on a fetch in flight return the context's
+				// error once the deadline expires.
+				if err := f(); err != nil && ctx.Err() == nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	hammer(func() error {
+		projects, err := c.FetchProjects(ctx, "e")
+		if err != nil {
+			return err
+		}
+		// Read everything.
+		_, err = json.Marshal(projects)
+		return err
+	})
+	hammer(func() error {
+		projects, err := c.FetchProjects(ctx, "e")
+		for i := 0; err == nil && i < len(projects); i++ {
+			var p *Project
+			if p, err = c.FetchProject(ctx, projects[i]); err == nil {
+				_, err = json.Marshal(p)
+			}
+		}
+		return err
+	})
+	hammer(func() error {
+		_, err := c.fetchProjects(ctx, "e")
+		return err
+	})
+	hammer(func() error {
+		projects, err := c.FetchProjects(ctx, "e")
+		if err == nil && len(projects) != 0 {
+			_, err = c.fetchProject(ctx, "", projects[0])
+		}
+		return err
+	})
+	hammer(func() error {
+		if _, err := c.FetchEvent(ctx, "e"); err != nil {
+			return err
+		}
+		_, err := c.FetchPrizes(ctx, "e")
+		return err
+	})
+	hammer(func() error {
+		if _, err := c.FetchChanges(ctx, "e", 0); err != nil {
+			return err
+		}
+		_, err := c.FetchHistory(ctx, "e", "1")
+		return err
+	})
+	hammer(func() error {
+		if err := c.saveCache(); err != nil {
+			return err
+		}
+		return store.Snapshot(io.Discard)
+	})
+	// The callers own the values returned.
+	hammer(func() error {
+		projects, err := c.FetchProjects(ctx, "e")
+		if err != nil || len(projects) == 0 {
+			return err
+		}
+		for _, p := range projects {
+			p.Title = "mutated"
+			p.Likes = -1
+			p.Team[0].Name = "mutated"
+		}
+		p, err := c.FetchProject(ctx, projects[0])
+		if err != nil {
+			return err
+		}
+		p.Description = "mutated"
+		if len(p.Tags) != 0 {
+			p.Tags[0] = "mutated"
+		}
+		info, err := c.FetchEvent(ctx, "e")
+		if err != nil {
+			return err
+		}
+		info.Title = "mutated"
+		return nil
+	})
+	wg.Wait()
+
+	c.mu.Lock()
+	b, err := json.Marshal(c.events["e"])
+	c.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(b, []byte("mutated")) {
+		t.Errorf("The cache was modified: %s", b)
+	}
+
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	p, err := store.GetProject("e", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Description == "" || p.Team[0].Role != "Everything" {
+		t.Errorf("Project details were lost: %+v", p)
+	}
+}
+
+// gatedClient blocks FetchProjects until gate is closed.
+type gatedClient struct {
+	fakeClient
+	gate  chan struct{}
+	calls atomic.Int32
+}
+
+func (g *gatedClient) FetchProjects(ctx context.Context, eventID string) ([]*Project, error) {
+	g.calls.Add(1)
+	<-g.gate
+	return g.fakeClient.FetchProjects(ctx, eventID)
+}
+
+func TestCachedClientCoalesce(t *testing.T) {
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "store.json"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	g := &gatedClient{gate: make(chan struct{})}
+	// The clock never advances so the scheduler doesn't fetch on its own.
+	d, err := NewCached(t.Context(), g, time.Hour, 30*time.Minute, store, SchedulerOptions{Clock: &fakeClock{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	// A viewer that leaves doesn't abort the fetch for the others.
+	ctx, cancel := context.WithCancel(t.Context())
+	cancelled := make(chan error)
+	go func() {
+		_, err := d.FetchProjects(ctx, "e")
+		cancelled <- err
+	}()
+	for g.calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-cancelled; err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			projects, err := d.FetchProjects(t.Context(), "e")
+			if err != nil || len(projects) != 10 {
+				t.Errorf("FetchProjects() = %d, %v", len(projects), err)
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(g.gate)
+	wg.Wait()
+	if n := g.calls.Load(); n != 1 {
+		t.Errorf("Expected 1 fetch, got %d", n)
+	}
+}
+
+func TestCachedClientStaleWhileRevalidate(t *testing.T) {
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "store.json"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	g := &gatedClient{gate: make(chan struct{})}
+	close(g.gate)
+	// The clock never advances so the scheduler doesn't fetch on its own.
+	d, err := NewCached(t.Context(), g, time.Hour, 30*time.Minute, store, SchedulerOptions{Clock: &fakeClock{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	c := d.(*cachedClient)
+	if _, err := c.FetchProjects(t.Context(), "e"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Make the event stale and block the refresh.
+	g.gate = make(chan struct{})
+	c.mu.Lock()
+	c.update("e", func(e *Event) { e.LastRefresh = time.Now().Add(-2 * time.Hour) })
+	c.mu.Unlock()
+	projects, err := c.FetchProjects(t.Context(), "e")
+	if err != nil || len(projects) != 10 {
+		t.Fatalf("FetchProjects() = %d, %v", len(projects), err)
+	}
+	close(g.gate)
+	for start := time.Now(); ; time.Sleep(time.Millisecond) {
+		c.mu.Lock()
+		fresh := time.Since(c.events["e"].LastRefresh) < time.Hour
+		c.mu.Unlock()
+		if fresh {
+			break
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("the event was not refreshed in the background")
+		}
+	}
+	if n := g.calls.Load(); n != 2 {
+		t.Errorf("Expected 2 fetches, got %d", n)
+	}
+}
+
+func TestCachedClientConfigure(t *testing.T) {
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "store.json"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	// The clock never advances so the scheduler doesn't fetch on its own.
+	d, err := NewCached(t.Context(), &fakeClient{}, time.Hour, 30*time.Minute, store, SchedulerOptions{Clock: &fakeClock{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	c := d.(*cachedClient)
+	if _, err := c.FetchProjects(t.Context(), "e"); err != nil {
+		t.Fatal(err)
+	}
+	due := func() time.Duration {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.sched.tasks["projects/e/"].Due.Sub(c.events["e"].LastRefresh)
+	}
+	before := due()
+	if err := d.Configure(t.Context(), CacheSettings{Freshness: 4 * time.Hour, AutoRefresh: 30 * time.Minute, Events: map[string]EventSettings{"e": {AutoRefresh: 3 * time.Hour, Pinned: true}}}); err != nil {
+		t.Fatal(err)
+	}
+	// The override is applied to the queued refreshes. The weight of the event
+	// decays a bit in between.
+	if after := due(); after.Round(time.Minute) != (6 * before).Round(time.Minute) {
+		t.Errorf("Expected the refresh to be due after %s, got %s", 6*before, after)
+	}
+	// Pinned events are refreshed even when nobody requests them anymore.
+	c.mu.Lock()
+	e := c.update("e", func(e *Event) { e.LastRequested = time.Now().Add(-24 * time.Hour) })
+	if !c.active(e) {
+		t.Error("Expected the pinned event to be active")
+	}
+	c.mu.Unlock()
+	if err := d.Configure(t.Context(), CacheSettings{Freshness: time.Hour, AutoRefresh: 30 * time.Minute}); err != nil {
+		t.Fatal(err)
+	}
+	c.mu.Lock()
+	if c.active(e) {
+		t.Error("Expected the event to be inactive")
+	}
+	c.mu.Unlock()
+	if err := d.Configure(t.Context(), CacheSettings{Freshness: time.Minute, AutoRefresh: time.Hour}); err == nil {
+		t.Error("expected an error")
+	}
+	// The cached data is kept.
+	c.mu.Lock()
+	n := len(c.events["e"].Projects)
+	c.mu.Unlock()
+	if n != 10 {
+		t.Errorf("Expected 10 projects, got %d", n)
+	}
+}
+
+// TestCachedClientCopies checks that the values returned can be modified
+// without changing the cache.
+func TestCachedClientCopies(t *testing.T) {
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "store.json"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	d, err := NewCached(t.Context(), &fakeClient{}, time.Hour, 30*time.Minute, store, SchedulerOptions{Clock: &fakeClock{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	ctx := t.Context()
+	info, err := d.FetchEvent(ctx, "e")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info.Title = "mutated"
+	for range 2 {
+		projects, err := d.FetchProjects(ctx, "e")
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := d.FetchProject(ctx, projects[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range append(projects, p) {
+			p.Title = "mutated"
+			p.Team[0].Name = "mutated"
+			if len(p.Tags) != 0 {
+				p.Tags[0] = "mutated"
+			}
+		}
+	}
+	if info, err = d.FetchEvent(ctx, "e"); err != nil || info.Title == "mutated" {
+		t.Errorf("FetchEvent() = %+v, %v", info, err)
+	}
+	projects, err := d.FetchProjects(ctx, "e")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(projects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(b, []byte("mutated")) {
+		t.Errorf("The cache was modified: %s", b)
+	}
+}
+
+// commentsClient returns the next batch of comments on every FetchProject,
+// out of a thread of 3 comments.
+type commentsClient struct {
+	fakeClient
+	mu      sync.Mutex
+	batches [][]Comment
+}
+
+func (c *commentsClient) FetchProjects(ctx context.Context, eventID string) ([]*Project, error) {
+	projects, err := c.fakeClient.FetchProjects(ctx, eventID)
+	for _, p := range projects {
+		p.CommentCount = 3
+	}
+	return projects, err
+}
+
+func (c *commentsClient) FetchProject(ctx context.Context, p *Project) (*Project, error) {
+	p2, err := c.fakeClient.FetchProject(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	p2.Comments = c.batches[0]
+	c.batches = c.batches[1:]
+	c.mu.Unlock()
+	return p2, nil
+}
+
+func TestCachedClientComments(t *testing.T) {
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "store.json"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	day := func(d int) time.Time { return time.Date(2025, 6, d, 0, 0, 0, 0, time.UTC) }
+	a := Comment{ID: "1", Time: day(1), Text: "a"}
+	b := Comment{ID: "2", Time: day(2), Text: "b"}
+	cc := Comment{ID: "3", Time: day(3), Text: "c"}
+	f := &commentsClient{batches: [][]Comment{{b, a}, {cc, b}, {cc, b}}}
+	d, err := NewCached(t.Context(), f, time.Hour, 30*time.Minute, store, SchedulerOptions{Clock: &fakeClock{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	c := d.(*cachedClient)
+	projects, err := c.FetchProjects(t.Context(), "e")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p *Project
+	for range 3 {
+		if p, err = c.fetchProject(t.Context(), "", projects[0]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var ids []string
+	for _, c := range p.Comments {
+		ids = append(ids, c.ID)
+	}
+	if want := []string{"1", "2", "3"}; !slices.Equal(ids, want) {
+		t.Errorf("got %v, want %v", ids, want)
+	}
+	// The first fetch is the initial load and the last one has no new
+	// comment.
+	changes, err := c.FetchChanges(t.Context(), "e", 0)
+	if err != nil || len(changes) != 2 || !slices.Contains(changes[0].Fields, "comments") || slices.Contains(changes[1].Fields, "comments") {
+		t.Errorf("FetchChanges() = %+v, %v", changes, err)
+	}
+}
+
+// submissionsClient lists the project in the events "a" and "b" with
+// different challenges.
+type submissionsClient struct {
+	fakeClient
+}
+
+func (c *submissionsClient) FetchProject(ctx context.Context, p *Project) (*Project, error) {
+	p2, err := c.fakeClient.FetchProject(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	p2.Submissions = []Submission{{EventID: "a", Challenges: []string{"Track A"}}, {EventID: "b", Challenges: []string{"Track B"}}}
+	return p2, nil
+}
+
+func TestCachedClientSubmissions(t *testing.T) {
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "store.json"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	d, err := NewCached(t.Context(), &submissionsClient{}, time.Hour, 30*time.Minute, store, SchedulerOptions{Clock: &fakeClock{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	for _, id := range []string{"a", "b"} {
+		if _, err := d.FetchProjects(t.Context(), id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The first call fetches the page, the next ones are served from the cache.
+	for range 5 {
+		for _, tc := range []struct{ eventID, want string }{{"b", "Track B"}, {"a", "Track A"}, {"", "Track A"}} {
+			p, err := d.FetchProject(t.Context(), &Project{ID: "1", EventID: tc.eventID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(p.Challenges) != 1 || p.Challenges[0] != tc.want {
+				t.Errorf("%q: Unexpected challenges %v", tc.eventID, p.Challenges)
+			}
+		}
+	}
+}
+
+// teamClient lists a second member only on the project page.
+type teamClient struct {
+	fakeClient
+}
+
+func (c *teamClient) FetchProject(ctx context.Context, p *Project) (*Project, error) {
+	p2, err := c.fakeClient.FetchProject(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	p2.Team = append(p2.Team, Person{Name: "Bob", URL: "https://devpost.com/bob", Role: "Design"})
+	return p2, nil
+}
+
+func TestCachedClientTeam(t *testing.T) {
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "store.json"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	d, err := NewCached(t.Context(), &teamClient{}, time.Hour, 30*time.Minute, store, SchedulerOptions{Clock: &fakeClock{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	c := d.(*cachedClient)
+	projects, err := c.fetchProjects(t.Context(), "e")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.fetchProject(t.Context(), "", projects[0]); err != nil {
+		t.Fatal(err)
+	}
+	// The gallery only lists Alice; Bob and the roles are kept from the
+	// project page and the team isn't reported as changed.
+	if projects, err = c.fetchProjects(t.Context(), "e"); err != nil {
+		t.Fatal(err)
+	}
+	if team := projects[0].Team; len(team) != 2 || team[0].Role != "Everything" || team[1].Name != "Bob" {
+		t.Errorf("Unexpected team %+v", team)
+	}
+	changes, err := c.FetchChanges(t.Context(), "e", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ch := range changes {
+		if slices.Contains(ch.Fields, "team") {
+			t.Errorf("Unexpected change %+v", ch)
+		}
+	}
+}
+
+func TestCachedClientBudget(t *testing.T) {
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "store.json"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	d, err := NewCached(t.Context(), &fakeClient{}, time.Hour, 30*time.Minute, store, SchedulerOptions{Clock: &fakeClock{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	if _, err := d.FetchEvent(t.Context(), "e"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.FetchProjects(t.Context(), "e"); err != nil {
+		t.Fatal(err)
+	}
+	tasks, err := d.RefreshQueue(t.Context())
+	if err != nil || len(tasks) != 12 {
+		t.Fatalf("RefreshQueue() = %d tasks, %v", len(tasks), err)
+	}
+	// The event and project pages are on different hosts but are throttled
+	// together.
+	for _, task := range tasks {
+		if task.Host != devpostBudget {
+			t.Errorf("Unexpected budget %+v", task)
+		}
+	}
+}
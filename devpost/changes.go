@@ -0,0 +1,147 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devpost
+
+import (
+	"slices"
+	"time"
+)
+
+// maxChanges bounds the change log kept per event.
+const maxChanges = 1000
+
+// ChangeType is the kind of change detected between two refreshes.
+type ChangeType string
+
+const (
+	// ChangeAdded is a new submission.
+	ChangeAdded ChangeType = "added"
+	// ChangeRemoved is a project that is not listed anymore.
+	ChangeRemoved ChangeType = "removed"
+	// ChangeUpdated is a project that had some of its fields edited.
+	ChangeUpdated ChangeType = "updated"
+	// ChangeWinner is a project that became a winner.
+	ChangeWinner ChangeType = "winner"
+	// ChangeLikes is a project that had its likes count change.
+	ChangeLikes ChangeType = "likes"
+)
+
+// Change is one entry in the per event change log.
+type Change struct {
+	// Seq is monotonically increasing per event.
+	Seq       int64      `json:"seq"`
+	Time      time.Time  `json:"time"`
+	Type      ChangeType `json:"type"`
+	EventID   string     `json:"event_id"`
+	ProjectID string     `json:"project_id"`
+	Title     string     `json:"title"`
+	URL       string     `json:"url"`
+	// Fields lists the JSON name of the fields that changed for ChangeUpdated.
+	Fields []string `json:"fields,omitempty"`
+	// Likes is the new likes count, LikesDelta the difference with the
+	// previous refresh.
+	Likes      int `json:"likes"`
+	LikesDelta int `json:"likes_delta,omitempty"`
+}
+
+// diffProjects returns the changes between two lists of projects.
+func diffProjects(eventID string, old, projects []*Project, now time.Time) []Change {
+	var out []Change
+	oldProjects := make(map[string]*Project, len(old))
+	for _, p := range old {
+		oldProjects[p.ID] = p
+	}
+	seen := make(map[string]struct{}, len(projects))
+	for _, p := range projects {
+		seen[p.ID] = struct{}{}
+		o, ok := oldProjects[p.ID]
+		if !ok {
+			out = append(out, newChange(ChangeAdded, eventID, p, now))
+			continue
+		}
+		out = append(out, diffProject(eventID, o, p, now)...)
+	}
+	for _, p := range old {
+		if _, ok := seen[p.ID]; !ok {
+			out = append(out, newChange(ChangeRemoved, eventID, p, now))
+		}
+	}
+	return out
+}
+
+// diffProject returns the changes between two versions of the same project.
+func diffProject(eventID string, old, p *Project, now time.Time) []Change {
+	if old.Hash() == p.Hash() {
+		return nil
+	}
+	var out []Change
+	if !old.Winner && p.Winner {
+		out = append(out, newChange(ChangeWinner, eventID, p, now))
+	}
+	if fields := diffFields(old, p); len(fields) != 0 {
+		c := newChange(ChangeUpdated, eventID, p, now)
+		c.Fields = fields
+		out = append(out, c)
+	}
+	if old.Likes != p.Likes {
+		c := newChange(ChangeLikes, eventID, p, now)
+		c.LikesDelta = p.Likes - old.Likes
+		out = append(out, c)
+	}
+	return out
+}
+
+// diffFields returns the JSON name of the user visible fields that differ.
+//
+// Likes, winner and derived fields are reported as their own change type.
+func diffFields(old, p *Project) []string {
+	var out []string
+	add := func(name string, changed bool) {
+		if changed {
+			out = append(out, name)
+		}
+	}
+	add("title", old.Title != p.Title)
+	add("tagline", old.Tagline != p.Tagline)
+	add("url", old.URL != p.URL)
+	add("image", old.Image != p.Image)
+	add("team", !slices.Equal(old.Team, p.Team))
+	add("description", old.Description != p.Description)
+	add("tags", !slices.Equal(old.Tags, p.Tags))
+	add("gallery", !slices.Equal(old.Gallery, p.Gallery))
+	add("links", !slices.Equal(old.Links, p.Links))
+	add("video_url", old.VideoURL != p.VideoURL)
+	add("challenges", !slices.Equal(old.Challenges, p.Challenges))
+	add("prizes", !slices.Equal(old.Prizes, p.Prizes))
+	// The edits of the comments are not reported, only new or deleted ones.
+	add("comments", len(old.Comments) != len(p.Comments))
+	add("updates", len(old.Updates) != len(p.Updates))
+	return out
+}
+
+func newChange(t ChangeType, eventID string, p *Project, now time.Time) Change {
+	return Change{Time: now, Type: t, EventID: eventID, ProjectID: p.ID, Title: p.Title, URL: p.URL, Likes: p.Likes}
+}
+
+// addChanges assigns sequence numbers and appends the changes to the event's
+// log, keeping at most maxChanges.
+func (e *Event) addChanges(changes []Change) {
+	for i := range changes {
+		e.LastChangeSeq++
+		changes[i].Seq = e.LastChangeSeq
+	}
+	e.Changes = append(e.Changes, changes...)
+	if len(e.Changes) > maxChanges {
+		e.Changes = append([]Change(nil), e.Changes[len(e.Changes)-maxChanges:]...)
+	}
+}
+
+// changesSince returns a copy of the changes after seq.
+func (e *Event) changesSince(seq int64) []Change {
+	i, _ := slices.BinarySearchFunc(e.Changes, seq+1, func(c Change, s int64) int {
+		return int(c.Seq - s)
+	})
+	return slices.Clone(e.Changes[i:])
+}
@@ -0,0 +1,84 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devpost
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDiffProjects(t *testing.T) {
+	now := time.Now()
+	old := []*Project{
+		{ID: "1", Title: "One", Likes: 1},
+		{ID: "2", Title: "Two", Likes: 2},
+		{ID: "3", Title: "Three"},
+	}
+	projects := []*Project{
+		{ID: "1", Title: "One", Likes: 1},
+		{ID: "2", Title: "Two!", Likes: 5, Winner: true},
+		{ID: "4", Title: "Four"},
+	}
+	var got []ChangeType
+	for _, c := range diffProjects("e", old, projects, now) {
+		got = append(got, c.Type)
+		switch c.Type {
+		case ChangeUpdated:
+			if !slices.Equal(c.Fields, []string{"title"}) {
+				t.Errorf("Fields = %q", c.Fields)
+			}
+		case ChangeLikes:
+			if c.LikesDelta != 3 || c.Likes != 5 {
+				t.Errorf("Likes = %d, delta = %d", c.Likes, c.LikesDelta)
+			}
+		}
+	}
+	want := []ChangeType{ChangeWinner, ChangeUpdated, ChangeLikes, ChangeAdded, ChangeRemoved}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestEventChanges(t *testing.T) {
+	e := &Event{}
+	for range maxChanges + 5 {
+		e.addChanges([]Change{{Type: ChangeLikes}})
+	}
+	if len(e.Changes) != maxChanges || e.Changes[0].Seq != 6 {
+		t.Fatalf("Unexpected log: len=%d first=%d", len(e.Changes), e.Changes[0].Seq)
+	}
+	if got := e.changesSince(int64(maxChanges + 3)); len(got) != 2 || got[0].Seq != maxChanges+4 {
+		t.Errorf("Unexpected changes: %v", got)
+	}
+	if got := e.changesSince(0); len(got) != maxChanges {
+		t.Errorf("Expected %d changes, got %d", maxChanges, len(got))
+	}
+}
+
+func TestWaitChanges(t *testing.T) {
+	e := &Event{ID: "e"}
+	c := &cachedClient{events: map[string]*Event{"e": e}, changed: make(chan struct{})}
+	done := make(chan []Change)
+	go func() {
+		changes, err := c.WaitChanges(t.Context(), "e", 0)
+		if err != nil {
+			t.Error(err)
+		}
+		done <- changes
+	}()
+	c.mu.Lock()
+	c.addChanges(e, []Change{{Type: ChangeAdded, ProjectID: "1"}})
+	c.mu.Unlock()
+	if changes := <-done; len(changes) != 1 || changes[0].Seq != 1 {
+		t.Errorf("Unexpected changes: %v", changes)
+	}
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if _, err := c.WaitChanges(ctx, "e", 1); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
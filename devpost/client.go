@@ -11,16 +11,22 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
+	"math"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
-	"os"
 	"path"
+	"regexp"
+	"slices"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/maruel/devpostdash/dom"
@@ -31,10 +37,65 @@ type Person struct {
 	Name      string `json:"name"`
 	URL       string `json:"url"`
 	AvatarURL string `json:"avatar_url"`
+	// Role is what the person did in the project, as written in the "Built
+	// by" section of the project page.
+	Role string `json:"role"`
+}
+
+// Image is one screenshot of the project's gallery.
+type Image struct {
+	URL     string `json:"url"`
+	Caption string `json:"caption"`
+}
+
+// LinkKind classifies a project link.
+type LinkKind string
+
+const (
+	LinkRepo   LinkKind = "repo"
+	LinkDemo   LinkKind = "demo"
+	LinkVideo  LinkKind = "video"
+	LinkSlides LinkKind = "slides"
+	LinkOther  LinkKind = "other"
+)
+
+// Link is an external link listed on the project page.
+type Link struct {
+	Kind  LinkKind `json:"kind"`
+	Label string   `json:"label"`
+	URL   string   `json:"url"`
+}
+
+// Comment is a comment on a project page, or an update posted by the team.
+type Comment struct {
+	// ID is devpost's ID of the comment, if any.
+	ID     string    `json:"id"`
+	Author Person    `json:"author"`
+	Time   time.Time `json:"time,omitzero"`
+	// Text is the content formatted as Markdown.
+	Text string `json:"text"`
+}
+
+// key identifies the comment across fetches. The text is not part of it so
+// an edited comment replaces the previous version, unless the comment has
+// neither ID nor time.
+func (c *Comment) key() string {
+	if c.ID != "" {
+		return c.ID
+	}
+	if c.Time.IsZero() {
+		return c.Author.URL + "\x00" + c.Text
+	}
+	return c.Author.URL + "\x00" + c.Time.UTC().Format(time.RFC3339)
 }
 
 type Project struct {
-	ID        string   `json:"id"`
+	ID string `json:"id"`
+	// EventID is the event the project was submitted to, to tell the projects
+	// apart when several events are shown together. The Client doesn't set it;
+	// the cached client's FetchProject uses it to pick the event of a project
+	// submitted to several.
+	EventID   string   `json:"event_id,omitempty"`
 	ShortName string   `json:"short_name"`
 	Title     string   `json:"title"`
 	URL       string   `json:"url"`
@@ -43,42 +104,279 @@ type Project struct {
 	Winner    bool     `json:"winner"`
 	Team      []Person `json:"team"`
 	Likes     int      `json:"likes"`
+	// CommentCount is the number of comments shown on the gallery card.
+	CommentCount int `json:"comment_count"`
+	// LikesVelocity is the number of likes per hour gained recently. It is
+	// computed by the cached client.
+	LikesVelocity float64 `json:"likes_velocity"`
 
 	// These are loaded by fetchProject:
 	Description   string   `json:"description"`
 	DescriptionMD string   `json:"description_md"`
 	Tags          []string `json:"tags"`
+	// Gallery is the screenshot carousel.
+	Gallery []Image `json:"gallery"`
+	// Links are the "Try it out" links.
+	Links []Link `json:"links"`
+	// VideoURL is the embedded demo video, if any.
+	VideoURL string `json:"video_url"`
+	// Challenges is the name of the challenges (tracks) the project was
+	// submitted to, as listed in the "Submitted to" section.
+	Challenges []string `json:"challenges"`
+	// Prizes is the name of the prizes won, as listed in the "Submitted to"
+	// section.
+	Prizes []string `json:"prizes"`
+	// Submissions lists the challenges and prizes of each hackathon the
+	// project was submitted to. The cached client only keeps the ones of the
+	// event in Challenges and Prizes.
+	Submissions []Submission `json:"submissions,omitempty"`
+	// Comments is the comment thread, oldest first. The cached client keeps
+	// the comments that are not listed on the project page anymore, unless
+	// the page lists the whole thread.
+	Comments []Comment `json:"comments"`
+	// Updates are the posts of the team, oldest first.
+	Updates []Comment `json:"updates"`
 
 	LastRefresh time.Time `json:"last_refresh,omitzero"`
 }
 
+// copyDetails copies the fields that are only loaded by FetchProject, except
+// the team roles.
+func (p *Project) copyDetails(src *Project) {
+	p.Description = src.Description
+	p.DescriptionMD = src.DescriptionMD
+	p.Tags = src.Tags
+	p.Gallery = src.Gallery
+	p.Links = src.Links
+	p.VideoURL = src.VideoURL
+	p.Challenges = src.Challenges
+	p.Prizes = src.Prizes
+	p.Submissions = src.Submissions
+	p.Comments = src.Comments
+	p.Updates = src.Updates
+	p.LastRefresh = src.LastRefresh
+}
+
+// Submission is the entry of a project in one hackathon.
+type Submission struct {
+	// EventID is empty if the hackathon is not hosted on devpost.com.
+	EventID    string   `json:"event_id"`
+	Challenges []string `json:"challenges"`
+	Prizes     []string `json:"prizes"`
+}
+
+// keepEvent keeps only the challenges and prizes of the event in Challenges
+// and Prizes, if the project page lists the event.
+func (p *Project) keepEvent(eventID string) {
+	if i := slices.IndexFunc(p.Submissions, func(s Submission) bool { return s.EventID == eventID }); i != -1 {
+		p.Challenges = p.Submissions[i].Challenges
+		p.Prizes = p.Submissions[i].Prizes
+	}
+}
+
+// clone returns a deep copy of the project.
+func (p *Project) clone() *Project {
+	out := *p
+	out.Team = slices.Clone(p.Team)
+	out.Tags = slices.Clone(p.Tags)
+	out.Gallery = slices.Clone(p.Gallery)
+	out.Links = slices.Clone(p.Links)
+	out.Challenges = slices.Clone(p.Challenges)
+	out.Prizes = slices.Clone(p.Prizes)
+	out.Submissions = slices.Clone(p.Submissions)
+	for i := range out.Submissions {
+		out.Submissions[i].Challenges = slices.Clone(out.Submissions[i].Challenges)
+		out.Submissions[i].Prizes = slices.Clone(out.Submissions[i].Prizes)
+	}
+	out.Comments = slices.Clone(p.Comments)
+	out.Updates = slices.Clone(p.Updates)
+	return &out
+}
+
+// cloneProjects returns a deep copy of the projects.
+func cloneProjects(projects []*Project) []*Project {
+	out := make([]*Project, len(projects))
+	for i, p := range projects {
+		out[i] = p.clone()
+	}
+	return out
+}
+
 func (p *Project) Hash() string {
 	p2 := *p
+	p2.EventID = ""
 	p2.LastRefresh = time.Time{}
 	b, _ := json.Marshal(&p2)
 	h := sha256.Sum256(b)
 	return hex.EncodeToString(h[:16])
 }
 
+// EventInfo is the hackathon level metadata scraped from the event's landing
+// page.
+type EventInfo struct {
+	ID           string    `json:"id"`
+	Title        string    `json:"title"`
+	Tagline      string    `json:"tagline"`
+	URL          string    `json:"url"`
+	BannerURL    string    `json:"banner_url"`
+	StartsAt     time.Time `json:"starts_at,omitzero"`
+	EndsAt       time.Time `json:"ends_at,omitzero"`
+	Deadline     time.Time `json:"deadline,omitzero"`
+	Location     string    `json:"location"`
+	PrizeTotal   string    `json:"prize_total"`
+	PrizeAmount  int       `json:"prize_amount"`
+	Participants int       `json:"participants"`
+	// Challenges is the list of challenges (tracks) projects can be submitted
+	// to.
+	Challenges []string `json:"challenges"`
+
+	LastRefresh time.Time `json:"last_refresh,omitzero"`
+}
+
+// clone returns a deep copy of the event info.
+func (e *EventInfo) clone() *EventInfo {
+	out := *e
+	out.Challenges = slices.Clone(e.Challenges)
+	return &out
+}
+
+// Prize is one prize of the hackathon, as listed on the prizes page.
+type Prize struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Value is the prize value as displayed, e.g. "$10,000 in cash".
+	Value    string `json:"value"`
+	Quantity int    `json:"quantity"`
+	Sponsor  string `json:"sponsor"`
+}
+
 type Event struct {
-	ID            string     `json:"id"`
-	Projects      []*Project `json:"projects"`
-	LastRefresh   time.Time  `json:"last_refresh,omitzero"`
-	LastRequested time.Time  `json:"last_requested,omitzero"`
+	ID       string     `json:"id"`
+	Info     *EventInfo `json:"info,omitempty"`
+	Prizes   []Prize    `json:"prizes,omitempty"`
+	Projects []*Project `json:"projects"`
+	// History is the popularity time series for each project ID.
+	History map[string][]Sample `json:"history,omitempty"`
+	// Changes is the bounded log of changes detected between refreshes.
+	Changes       []Change `json:"changes,omitempty"`
+	LastChangeSeq int64    `json:"last_change_seq,omitempty"`
+	// GalleryHash is the hash of the project gallery pages as of LastRefresh.
+	GalleryHash string `json:"gallery_hash,omitempty"`
+	// Discovery is the strategy that listed the projects as of LastRefresh.
+	Discovery Discovery `json:"discovery,omitempty"`
+	// Check is the validation of the latest project list parsed, even if it
+	// was rejected.
+	Check             *ScrapeCheck `json:"check,omitempty"`
+	LastRefresh       time.Time    `json:"last_refresh,omitzero"`
+	LastPrizesRefresh time.Time    `json:"last_prizes_refresh,omitzero"`
+	LastRequested     time.Time    `json:"last_requested,omitzero"`
+	// Requests is the number of requests as of LastRequested, decayed
+	// exponentially over time. See weight.
+	Requests float64 `json:"requests,omitempty"`
+}
+
+// requestsHalfLife is the half-life of Event.Requests.
+const requestsHalfLife = 15 * time.Minute
+
+// weight returns how much the event is being looked at. It is the number of
+// requests with a half-life of requestsHalfLife.
+func (e *Event) weight(now time.Time) float64 {
+	if e.LastRequested.IsZero() {
+		return 0
+	}
+	return e.Requests * math.Exp2(-float64(now.Sub(e.LastRequested))/float64(requestsHalfLife))
+}
+
+// touch records a request for the event.
+func (e *Event) touch(now time.Time) {
+	e.Requests = e.weight(now) + 1
+	e.LastRequested = now
 }
 
+// Client scrapes devpost.com.
 type Client interface {
 	io.Closer
+	FetchEvent(ctx context.Context, eventID string) (*EventInfo, error)
+	FetchPrizes(ctx context.Context, eventID string) ([]Prize, error)
 	FetchProjects(ctx context.Context, eventID string) ([]*Project, error)
-	FetchProject(ctx context.Context, p *Project) error
+	// FetchProject returns a copy of p with the details from the project page.
+	// p is not modified.
+	FetchProject(ctx context.Context, p *Project) (*Project, error)
+	// FetchPerson returns a participant with their profile. The CachedClient
+	// also returns their projects across the events and only knows the
+	// participants of the cached events.
+	FetchPerson(ctx context.Context, username string) (*Participant, error)
+	// ListEvents returns a page of the hackathons listed on devpost.com that
+	// match the query.
+	ListEvents(ctx context.Context, q EventQuery) ([]Hackathon, error)
+	// Upstream returns the health of devpost, e.g. whether it is rate
+	// limiting us.
+	Upstream(ctx context.Context) (*UpstreamStatus, error)
+}
+
+// CachedClient is the Client returned by NewCached. It also tracks how the
+// events change between refreshes and indexes their participants.
+//
+// The events and projects returned are copies that the caller may modify.
+type CachedClient interface {
+	Client
+	// FetchHistory returns the likes and comments time series of a project.
+	FetchHistory(ctx context.Context, eventID, projectID string) ([]Sample, error)
+	// FetchChanges returns the changes detected after the sequence number
+	// since.
+	FetchChanges(ctx context.Context, eventID string, since int64) ([]Change, error)
+	// WaitChanges is like FetchChanges but blocks until there is at least one
+	// change or the context is canceled.
+	WaitChanges(ctx context.Context, eventID string, since int64) ([]Change, error)
+	// RefreshQueue returns the pending background refreshes, in the order they
+	// will be run.
+	RefreshQueue(ctx context.Context) ([]ScheduledTask, error)
+	// ScraperHealth returns how well the latest project lists parsed, to
+	// detect devpost markup changes.
+	ScraperHealth(ctx context.Context) (*ScraperHealth, error)
+	// FetchPeople returns the participants of an event with their projects in
+	// the event, sorted by name.
+	FetchPeople(ctx context.Context, eventID string) ([]*Participant, error)
+	// Configure replaces the refresh settings and reschedules the background
+	// refreshes. The cached data is kept.
+	Configure(ctx context.Context, s CacheSettings) error
 }
 
 type client struct {
-	c      http.Client
-	header http.Header
+	c       http.Client
+	header  http.Header
+	retry   RetryPolicy
+	breaker breaker
+	sleep   func(ctx context.Context, d time.Duration) error
+	pages   pageCache
+	// galleryConcurrency is the number of gallery pages fetched concurrently.
+	galleryConcurrency int
+	discovery          []Discovery
+}
+
+// ClientOptions configures the Client returned by New. The zero value is
+// valid.
+type ClientOptions struct {
+	// Retry defaults to DefaultRetryPolicy, field by field.
+	Retry RetryPolicy
+	// GalleryConcurrency is the number of project gallery pages fetched
+	// concurrently. The throttle of the transport still applies. Defaults to
+	// 4.
+	GalleryConcurrency int
+	// Discovery is the order in which the strategies to list the projects are
+	// tried, until one finds projects. Defaults to DefaultDiscovery.
+	Discovery []Discovery
+	// Referer is sent with every request. Defaults to https://devpost.com/.
+	Referer string
 }
 
-func New(ctx context.Context, h http.RoundTripper) (Client, error) {
+// New returns a Client fetching from devpost.com.
+func New(ctx context.Context, h http.RoundTripper, opts ClientOptions) (Client, error) {
+	for _, s := range opts.Discovery {
+		if discoverers[s] == nil {
+			return nil, fmt.Errorf("unknown discovery strategy %q", s)
+		}
+	}
 	jar, err := cookiejar.New(nil)
 	if err != nil {
 		return nil, err
@@ -86,13 +384,8 @@ func New(ctx context.Context, h http.RoundTripper) (Client, error) {
 	jar.SetCookies(&url.URL{Scheme: "https", Host: "devpost.com"}, []*http.Cookie{
 		{Name: "platform.notifications.newsletter.dismissed", Value: "dismissed"},
 	})
-	out := &client{
-		header: http.Header{
-			"Referer":    []string{"https://vibe-coding-hackathon.devpost.com/rules"},
-			"User-Agent": []string{"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/137.0.0.0 Safari/537.36"},
-		},
-		c: http.Client{Transport: h, Jar: jar},
-	}
+	out := newClient(h, opts)
+	out.c.Jar = jar
 	// Load cookies.
 	_, err = out.get(ctx, "https://devpost.com")
 	if err != nil {
@@ -101,122 +394,175 @@ func New(ctx context.Context, h http.RoundTripper) (Client, error) {
 	return out, nil
 }
 
-func (d *client) get(ctx context.Context, url string) ([]byte, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, err
+func newClient(h http.RoundTripper, opts ClientOptions) *client {
+	opts.Retry = opts.Retry.withDefaults()
+	if opts.GalleryConcurrency <= 0 {
+		opts.GalleryConcurrency = 4
 	}
-	resp, err := d.c.Do(req)
-	if err != nil {
-		return nil, err
+	if len(opts.Discovery) == 0 {
+		opts.Discovery = DefaultDiscovery
 	}
-	bod, err := io.ReadAll(resp.Body)
-	_ = resp.Body.Close()
-	if err != nil {
-		return nil, err
+	if opts.Referer == "" {
+		opts.Referer = "https://devpost.com/"
 	}
-	if resp.StatusCode != 200 {
-		err = &HTTPError{StatusCode: resp.StatusCode, Body: bod}
+	return &client{
+		header: http.Header{
+			"Referer":    []string{opts.Referer},
+			"User-Agent": []string{"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/137.0.0.0 Safari/537.36"},
+		},
+		c:                  http.Client{Transport: h},
+		retry:              opts.Retry,
+		breaker:            breaker{threshold: opts.Retry.BreakerThreshold, cooldown: opts.Retry.BreakerCooldown, now: time.Now},
+		sleep:              sleep,
+		galleryConcurrency: opts.GalleryConcurrency,
+		discovery:          opts.Discovery,
 	}
-	return bod, err
+}
+
+func (d *client) Upstream(ctx context.Context) (*UpstreamStatus, error) {
+	return d.breaker.upstream(), nil
+}
+
+func (d *client) forgetEvent(eventID string) {
+	d.pages.forgetEvent(eventID)
 }
 
 func (d *client) Close() error {
 	return nil
 }
 
-func (d *client) FetchProjects(ctx context.Context, eventID string) ([]*Project, error) {
-	var projects []*Project
+// FetchPerson returns the person's profile. The projects are only indexed by
+// the cached client.
+func (d *client) FetchPerson(ctx context.Context, username string) (*Participant, error) {
+	if !reUsername.MatchString(username) {
+		return nil, &HTTPError{StatusCode: http.StatusBadRequest, Body: []byte("invalid username")}
+	}
 	var err error
+	var st pageStats
 	start := time.Now()
 	defer func() {
-		slog.InfoContext(ctx, "devpost", "projects", len(projects), "dur", time.Since(start), "err", err)
+		slog.InfoContext(ctx, "devpost", "person", username, "dur", time.Since(start), "cached", st.hits != 0, "err", err)
 	}()
-	/*
-		projects, err = d.fetchProjectsFromSubmissions(ctx, eventID)
-		if err != nil {
-			return nil, err
-		}
-		if len(projects) != 0 {
-			return projects, nil
-		}
-	*/
-	projects, err = d.fetchProjectsNew(ctx, eventID)
-	if err != nil {
+	u := "https://devpost.com/" + username
+	var parsed *Profile
+	if parsed, _, err = fetchParsed(ctx, d, u, &st, func(bod []byte) (*Profile, error) {
+		return parseProfile(bytes.NewReader(bod))
+	}); err != nil {
 		return nil, err
 	}
-	return projects, nil
+	prof := *parsed
+	prof.LastRefresh = time.Now()
+	return &Participant{
+		Person:   Person{Name: prof.Name, URL: u, AvatarURL: prof.AvatarURL},
+		Username: username,
+		Profile:  &prof,
+	}, nil
 }
 
-func (d *client) fetchProjectsFromSubmissions(ctx context.Context, eventID string) ([]*Project, error) {
-	var projects []*Project
+func (d *client) ListEvents(ctx context.Context, q EventQuery) ([]Hackathon, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	var hackathons []Hackathon
 	var err error
-	for i := 1; ; i++ {
-		url := fmt.Sprintf("https://%s.devpost.com/submissions/search?page=%d&sort=alpha&terms=&utf8=%%E2%%9C%%93", eventID, i)
-		var bod []byte
-		if bod, err = d.get(ctx, url); err != nil {
-			return projects, err
-		}
-		// A bit of a hack but good enough.
-		if bytes.Contains(bod, []byte("There are no submissions which match your criteria.")) {
-			break
-		}
-		if bytes.Contains(bod, []byte("The hackathon managers haven't published this gallery yet, but hang tight!")) {
-			break
-		}
-		var p []*Project
-		if p, err = parseProjects(bytes.NewReader(bod)); err != nil {
-			return projects, err
-		}
-		if len(p) == 0 {
-			break
-		}
-		projects = append(projects, p...)
+	start := time.Now()
+	u := q.url()
+	defer func() {
+		slog.InfoContext(ctx, "devpost", "hackathons", len(hackathons), "url", u, "dur", time.Since(start), "err", err)
+	}()
+	// The page cache is not used since there's a page for every search. The
+	// cached client keeps the latest ones.
+	var bod []byte
+	if bod, err = d.get(ctx, u); err != nil {
+		return nil, err
 	}
-	return projects, nil
+	if hackathons, err = parseHackathons(bod); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for i := range hackathons {
+		hackathons[i].LastRefresh = now
+	}
+	return hackathons, nil
 }
 
-func (d *client) fetchProjectsNew(ctx context.Context, eventID string) ([]*Project, error) {
-	var projects []*Project
+func (d *client) FetchEvent(ctx context.Context, eventID string) (*EventInfo, error) {
+	var info *EventInfo
 	var err error
+	var st pageStats
 	start := time.Now()
 	defer func() {
-		slog.InfoContext(ctx, "devpost", "projects_new", len(projects), "dur", time.Since(start), "err", err)
+		slog.InfoContext(ctx, "devpost", "event", eventID, "dur", time.Since(start), "cached", st.hits != 0, "err", err)
 	}()
-	for i := 1; ; i++ {
-		url := fmt.Sprintf("https://%s.devpost.com/project-gallery?page=%d", eventID, i)
-		var bod []byte
-		if bod, err = d.get(ctx, url); err != nil {
-			return projects, err
-		}
-		if bytes.Contains(bod, []byte("The hackathon managers haven't published this gallery yet, but hang tight!")) {
-			break
-		}
-		var p []*Project
-		if p, err = parseProjects(bytes.NewReader(bod)); err != nil {
-			return projects, err
-		}
-		if len(p) == 0 {
-			break
-		}
-		projects = append(projects, p...)
+	url := fmt.Sprintf("https://%s.devpost.com/", eventID)
+	var parsed *EventInfo
+	if parsed, _, err = fetchParsed(ctx, d, url, &st, func(bod []byte) (*EventInfo, error) {
+		return parseEventInfo(bytes.NewReader(bod))
+	}); err != nil {
+		return nil, err
 	}
-	return projects, nil
+	c := *parsed
+	info = &c
+	info.ID = eventID
+	info.URL = url
+	info.LastRefresh = time.Now()
+	return info, nil
+}
+
+func (d *client) FetchPrizes(ctx context.Context, eventID string) ([]Prize, error) {
+	var prizes []Prize
+	var err error
+	var st pageStats
+	start := time.Now()
+	defer func() {
+		slog.InfoContext(ctx, "devpost", "prizes", len(prizes), "event", eventID, "dur", time.Since(start), "cached", st.hits != 0, "err", err)
+	}()
+	if prizes, _, err = fetchParsed(ctx, d, fmt.Sprintf("https://%s.devpost.com/prizes", eventID), &st, func(bod []byte) ([]Prize, error) {
+		return parsePrizes(bytes.NewReader(bod))
+	}); err != nil {
+		return nil, err
+	}
+	prizes = slices.Clone(prizes)
+	return prizes, nil
+}
+
+func (d *client) FetchProjects(ctx context.Context, eventID string) ([]*Project, error) {
+	g, err := d.fetchGallery(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	return g.projects, nil
 }
 
-func (d *client) FetchProject(ctx context.Context, project *Project) error {
+func (d *client) FetchProject(ctx context.Context, project *Project) (*Project, error) {
 	start := time.Now()
 	var err error
+	var st pageStats
 	defer func() {
-		slog.InfoContext(ctx, "devpost", "project", project.ShortName, "dur", time.Since(start), "err", err)
+		slog.InfoContext(ctx, "devpost", "project", project.ShortName, "dur", time.Since(start), "cached", st.hits != 0, "err", err)
 	}()
 
-	var bod []byte
-	if bod, err = d.get(ctx, project.URL); err != nil {
-		return err
+	// Parse into an empty project so the result doesn't depend on project and
+	// can be reused while the page is unchanged.
+	var details *Project
+	if details, _, err = fetchParsed(ctx, d, project.URL, &st, func(bod []byte) (*Project, error) {
+		p := &Project{}
+		return p, parseProjectPage(bytes.NewReader(bod), p)
+	}); err != nil {
+		return nil, err
 	}
-	var doc *html.Node
-	if doc, err = html.Parse(bytes.NewReader(bod)); err != nil {
+	p := *project
+	p.copyDetails(details)
+	p.Team = addMembers(slices.Clone(project.Team), details.Team)
+	p.LastRefresh = time.Now()
+	return &p, nil
+}
+
+// parseProjectPage fills the project's fields that are only available on the
+// project page.
+func parseProjectPage(r io.Reader, project *Project) error {
+	doc, err := html.Parse(r)
+	if err != nil {
 		return err
 	}
 	if d := dom.FirstChild(doc, dom.Tag("div"), dom.ID("app-details-left")); d != nil {
@@ -229,232 +575,1023 @@ func (d *client) FetchProject(ctx context.Context, project *Project) error {
 			project.Tags = append(project.Tags, dom.NodeText(c))
 		}
 	}
-	project.LastRefresh = time.Now()
+	project.Gallery = nil
+	if d := dom.FirstChild(doc, dom.ID("gallery")); d != nil {
+		for li := range dom.YieldChildren(d, dom.Tag("li")) {
+			img := dom.FirstChild(li, dom.Tag("img"))
+			if img == nil {
+				continue
+			}
+			i := Image{URL: normalizeURL(dom.NodeAttr(img, "src"))}
+			if a := dom.FirstChild(li, dom.Tag("a")); a != nil {
+				if u := normalizeURL(dom.NodeAttr(a, "href")); u != "" {
+					i.URL = u
+				}
+			}
+			if c := dom.FirstChild(li, dom.Tag("p")); c != nil {
+				i.Caption = dom.NodeText(c)
+			} else if c := dom.FirstChild(li, dom.Tag("figcaption")); c != nil {
+				i.Caption = dom.NodeText(c)
+			}
+			if i.URL != "" {
+				project.Gallery = append(project.Gallery, i)
+			}
+		}
+	}
+	if d := dom.FirstChild(doc, dom.ID("app-team")); d != nil {
+		for li := range dom.YieldChildren(d, dom.Tag("li"), dom.Class("software-team-member")) {
+			a := dom.FirstChild(li, dom.Tag("a"), dom.Class("user-profile-link"))
+			if a == nil {
+				continue
+			}
+			person := Person{URL: dom.NodeAttr(a, "href")}
+			if img := dom.FirstChild(a, dom.Tag("img")); img != nil {
+				person.Name = dom.NodeAttr(img, "alt")
+				person.AvatarURL = dom.NodeAttr(img, "src")
+			}
+			if b := dom.FirstChild(li, dom.Tag("p"), dom.Class("bubble")); b != nil {
+				person.Role = dom.NodeText(b)
+			}
+			project.Team = addMembers(project.Team, []Person{person})
+		}
+	}
+	project.Links = nil
+	project.VideoURL = ""
+	if d := dom.FirstChild(doc, dom.Tag("ul"), dom.Attr("data-role", "software-urls")); d != nil {
+		for a := range dom.YieldChildren(d, dom.Tag("a")) {
+			u := normalizeURL(dom.NodeAttr(a, "href"))
+			if u == "" {
+				continue
+			}
+			project.Links = append(project.Links, Link{Kind: classifyLink(u), Label: dom.NodeText(a), URL: u})
+		}
+	}
+	if d := dom.FirstChild(doc, dom.ID("gallery")); d != nil {
+		for f := range dom.YieldChildren(d, dom.Tag("iframe")) {
+			if u := normalizeURL(dom.NodeAttr(f, "src")); classifyLink(u) == LinkVideo {
+				project.VideoURL = u
+				break
+			}
+		}
+	}
+	// Each hackathon the project was submitted to has its own block. Without
+	// knowing the event, Challenges and Prizes are the union of all of them.
+	project.Challenges = nil
+	project.Prizes = nil
+	project.Submissions = nil
+	if d := dom.FirstChild(doc, dom.Tag("div"), dom.ID("submissions")); d != nil {
+		for l := range dom.YieldChildren(d, dom.Class("software-list-content")) {
+			var sub Submission
+			if a := dom.FirstChild(l, dom.Tag("a")); a != nil {
+				sub.EventID = eventIDFromURL(dom.NodeAttr(a, "href"))
+			}
+			for c := range dom.YieldChildren(l, dom.Tag("li")) {
+				name := dom.NodeText(c)
+				winner := dom.FirstChild(c, dom.Tag("span"), dom.Class("winner"))
+				if winner != nil {
+					name = strings.TrimSpace(strings.TrimPrefix(name, dom.NodeText(winner)))
+				}
+				if name == "" {
+					continue
+				}
+				sub.Challenges = append(sub.Challenges, name)
+				if winner != nil {
+					sub.Prizes = append(sub.Prizes, name)
+				}
+			}
+			project.Challenges = append(project.Challenges, sub.Challenges...)
+			project.Prizes = append(project.Prizes, sub.Prizes...)
+			project.Submissions = append(project.Submissions, sub)
+		}
+	}
+	project.Comments = parseComments(doc, "comments", "comment", "comment-body")
+	project.Updates = parseComments(doc, "updates", "update", "update-body")
 	return nil
 }
 
+// parseComments returns the posts listed in the element with the ID
+// containerID, oldest first. Each post is an element with the class item and
+// its content is in the element with the class body.
+func parseComments(doc *html.Node, containerID, item, body string) []Comment {
+	d := dom.FirstChild(doc, dom.ID(containerID))
+	if d == nil {
+		return nil
+	}
+	var out []Comment
+	for n := range dom.YieldChildren(d, dom.Class(item)) {
+		c := Comment{ID: strings.TrimPrefix(dom.NodeAttr(n, "id"), item+"-")}
+		if a := dom.FirstChild(n, dom.Tag("a"), dom.Class("user-profile-link")); a != nil {
+			c.Author.URL = dom.NodeAttr(a, "href")
+			if img := dom.FirstChild(a, dom.Tag("img")); img != nil {
+				c.Author.Name = dom.NodeAttr(img, "alt")
+				c.Author.AvatarURL = dom.NodeAttr(img, "src")
+			} else {
+				c.Author.Name = dom.NodeText(a)
+			}
+		}
+		if t := dom.FirstChild(n, dom.Tag("time")); t != nil {
+			c.Time = parseDate(dom.NodeAttr(t, "datetime"))
+		}
+		if b := dom.FirstChild(n, dom.Class(body)); b != nil {
+			c.Text = strings.TrimSpace(dom.NodeMarkdown(b))
+		}
+		if c.Text != "" {
+			out = append(out, c)
+		}
+	}
+	// devpost lists the newest first.
+	slices.SortStableFunc(out, func(a, b Comment) int { return a.Time.Compare(b.Time) })
+	return out
+}
+
+// cachedClient caches the events in memory.
+//
+// The cached values are immutable snapshots: an update stores a modified copy
+// of the Event in events, and a modified copy of the Project in a copy of
+// Event.Projects. This way the values handed out can be read without holding
+// mu. Event.Changes is append-only and Event.History is replaced on update.
 type cachedClient struct {
-	d           Client
-	freshness   time.Duration
-	autoRefresh time.Duration
-	stopRefresh time.Duration
-	cacheFile   string
+	d     Client
+	store Store
+	sched *Scheduler
+	// settings is replaced by Configure. Events in it must not be modified.
+	settings atomic.Pointer[CacheSettings]
 
 	mu     sync.Mutex
 	events map[string]*Event
+	// changed is closed and replaced whenever changes are recorded.
+	changed chan struct{}
+	// people indexes the team members of the events.
+	people peopleIndex
+	// profiles is the latest profile fetched for each username in lower case.
+	profiles map[string]profileEntry
+	// listings is the latest page of hackathons fetched for each query URL.
+	listings map[string]listingEntry
+
+	// Coalesce the concurrent fetches of the same resource.
+	infoFlight     flightGroup[*EventInfo]
+	prizesFlight   flightGroup[[]Prize]
+	projectsFlight flightGroup[[]*Project]
+	projectFlight  flightGroup[*Project]
+	profileFlight  flightGroup[*Participant]
+	listingFlight  flightGroup[[]Hackathon]
+	// revalidating tracks the background refreshes of stale data.
+	revalidating sync.WaitGroup
 
 	ctx    context.Context
 	cancel context.CancelFunc
+	// done is closed once the scheduler stopped.
+	done chan struct{}
 }
 
-func NewCached(parentCtx context.Context, d Client, freshness, autoRefresh time.Duration, cacheFilePath string) (Client, error) {
-	if freshness <= autoRefresh {
-		return nil, fmt.Errorf("freshness must be less than autoRefresh")
+// CacheSettings are the refresh settings of the Client returned by NewCached.
+type CacheSettings struct {
+	// Freshness is how long the cached data is returned as is. Stale data is
+	// refreshed in the background when requested. It must be longer than
+	// AutoRefresh.
+	Freshness time.Duration
+	// AutoRefresh is the interval at which the events are refreshed in the
+	// background, shortened when they are requested frequently.
+	AutoRefresh time.Duration
+	// StopRefresh is how long after their last request the events are still
+	// refreshed in the background. Defaults to 4 hours.
+	StopRefresh time.Duration
+	// Events overrides the settings of specific events, by event ID.
+	Events map[string]EventSettings
+}
+
+// EventSettings overrides the CacheSettings of one event.
+type EventSettings struct {
+	// AutoRefresh overrides CacheSettings.AutoRefresh if not zero.
+	AutoRefresh time.Duration
+	// Pinned events are refreshed in the background even when they are not
+	// requested anymore.
+	Pinned bool
+}
+
+// withDefaults returns a validated copy of the settings with the defaults
+// filled in.
+func (s *CacheSettings) withDefaults() (*CacheSettings, error) {
+	if s.Freshness <= s.AutoRefresh {
+		return nil, fmt.Errorf("freshness must be longer than autoRefresh")
+	}
+	out := *s
+	if out.StopRefresh <= 0 {
+		out.StopRefresh = 4 * time.Hour
+	}
+	out.Events = maps.Clone(s.Events)
+	return &out, nil
+}
+
+// NewCached returns a Client that caches d in memory and persists the events
+// in store. The caller keeps ownership of store and must close it after the
+// returned client.
+//
+// The events requested in the last 4 hours are refreshed in the background
+// every autoRefresh, more often when they are requested frequently. Use
+// Configure to change the settings.
+func NewCached(parentCtx context.Context, d Client, freshness, autoRefresh time.Duration, store Store, opts SchedulerOptions) (CachedClient, error) {
+	settings, err := (&CacheSettings{Freshness: freshness, AutoRefresh: autoRefresh}).withDefaults()
+	if err != nil {
+		return nil, err
 	}
 	ctx, cancel := context.WithCancel(parentCtx)
 	c := &cachedClient{
-		d:           d,
-		freshness:   freshness,
-		autoRefresh: autoRefresh,
-		stopRefresh: 4 * time.Hour,
-		cacheFile:   cacheFilePath,
-		events:      map[string]*Event{},
-		ctx:         ctx,
-		cancel:      cancel,
+		d:        d,
+		store:    store,
+		events:   map[string]*Event{},
+		changed:  make(chan struct{}),
+		profiles: map[string]profileEntry{},
+		listings: map[string]listingEntry{},
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
 	}
+	c.settings.Store(settings)
+	c.sched = NewScheduler(opts, c.runTask)
 	if err := c.loadCache(); err != nil {
+		cancel()
 		return nil, err
 	}
-	go c.autoRefreshLoop()
+	c.mu.Lock()
+	for _, e := range c.events {
+		c.plan(e)
+	}
+	c.mu.Unlock()
+	go func() {
+		defer close(c.done)
+		c.sched.Run(ctx)
+	}()
 	return c, nil
 }
 
 func (c *cachedClient) loadCache() error {
-	f, err := os.Open(c.cacheFile)
+	ids, err := c.store.ListEvents()
+	events := make(map[string]*Event, len(ids))
 	defer func() {
-		slog.InfoContext(c.ctx, "devpost", "msg", "loaded cache", "err", err, "path", c.cacheFile, "events", len(c.events))
+		slog.InfoContext(c.ctx, "devpost", "msg", "loaded cache", "err", err, "events", len(events))
 	}()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
 		return err
 	}
-	defer f.Close()
-	data := serializedCache{}
-	if err = json.NewDecoder(f).Decode(&data); err != nil {
+	for _, id := range ids {
+		if events[id], err = c.store.GetEvent(id); err != nil {
+			return err
+		}
+	}
+	c.mu.Lock()
+	c.events = events
+	for _, e := range events {
+		c.people.index(e)
+	}
+	c.mu.Unlock()
+	return nil
+}
+
+// Configure replaces the settings and reschedules the refreshes of all the
+// cached events.
+func (c *cachedClient) Configure(ctx context.Context, s CacheSettings) error {
+	settings, err := s.withDefaults()
+	if err != nil {
 		return err
 	}
 	c.mu.Lock()
-	c.events = data.Events
+	c.settings.Store(settings)
+	for _, e := range c.events {
+		c.plan(e)
+	}
+	n := len(c.events)
 	c.mu.Unlock()
+	slog.InfoContext(ctx, "devpost", "msg", "configured", "freshness", settings.Freshness, "autoRefresh", settings.AutoRefresh, "stopRefresh", settings.StopRefresh, "overrides", len(settings.Events), "events", n)
 	return nil
 }
 
+// Close stops the background refreshes and saves the cache.
 func (c *cachedClient) Close() error {
 	c.cancel()
+	<-c.done
+	c.revalidating.Wait()
+	c.infoFlight.wait()
+	c.prizesFlight.wait()
+	c.projectsFlight.wait()
+	c.projectFlight.wait()
+	c.profileFlight.wait()
+	c.listingFlight.wait()
 	return c.saveCache()
 }
 
 func (c *cachedClient) saveCache() error {
-	f, err := os.Create(c.cacheFile)
+	var err error
+	n := 0
 	defer func() {
-		slog.InfoContext(c.ctx, "devpost", "msg", "saved cache", "err", err, "events", len(c.events))
+		slog.InfoContext(c.ctx, "devpost", "msg", "saved cache", "err", err, "events", n)
 	}()
+	c.mu.Lock()
+	n = len(c.events)
+	for _, e := range c.events {
+		if err = c.store.PutEvent(e); err != nil {
+			break
+		}
+	}
+	c.mu.Unlock()
 	if err != nil {
 		return err
 	}
-	defer f.Close()
-	e := json.NewEncoder(f)
-	e.SetIndent("", "  ")
-
-	c.mu.Lock()
-	data := serializedCache{Version: 1, Events: c.events}
-	err = e.Encode(&data)
-	c.mu.Unlock()
+	err = c.store.Flush()
 	return err
 }
 
-func (c *cachedClient) autoRefreshLoop() {
-	ticker := time.NewTicker(time.Second)
-	defer ticker.Stop()
-	for {
-		select {
-		case <-c.ctx.Done():
-			return
-		case <-ticker.C:
-			eventID := ""
-			var p *Project
-			c.mu.Lock()
-			for id, event := range c.events {
-				// Do not auto-refresh events that have not been requested in a while.
-				if !event.LastRequested.IsZero() && time.Since(event.LastRequested) > c.stopRefresh {
-					continue
-				}
-				if since := time.Since(event.LastRefresh); since > c.autoRefresh {
-					eventID = id
-					break
-				}
-				for _, project := range event.Projects {
-					if since := time.Since(project.LastRefresh); since > c.autoRefresh {
-						p = project
-						break
-					}
-				}
-				if p != nil {
-					break
-				}
-			}
-			c.mu.Unlock()
+// update replaces the event with a copy modified by f, creating it if needed.
+// It returns the new snapshot. c.mu must be held.
+func (c *cachedClient) update(eventID string, f func(e *Event)) *Event {
+	e := &Event{ID: eventID}
+	old := c.events[eventID]
+	if old != nil {
+		*e = *old
+	}
+	f(e)
+	c.events[eventID] = e
+	if old == nil || !slices.Equal(old.Projects, e.Projects) {
+		c.people.index(e)
+	}
+	return e
+}
 
-			if p != nil {
-				slog.InfoContext(c.ctx, "devpost", "msg", "auto-refreshing project", "projectID", p.ID)
-				if err := c.fetchProject(c.ctx, p); err != nil {
-					slog.ErrorContext(c.ctx, "devpost", "msg", "failed to auto-refresh project", "projectID", p.ID, "err", err)
-				}
-			} else if eventID != "" {
-				slog.InfoContext(c.ctx, "devpost", "msg", "auto-refreshing event", "eventID", eventID)
-				if _, err := c.fetchProjects(c.ctx, eventID); err != nil {
-					slog.ErrorContext(c.ctx, "devpost", "msg", "failed to auto-refresh event", "eventID", eventID, "err", err)
-				}
-			}
-		}
+// putEvent persists the event. c.mu must be held.
+func (c *cachedClient) putEvent(e *Event) {
+	if err := c.store.PutEvent(e); err != nil {
+		slog.ErrorContext(c.ctx, "devpost", "msg", "failed to store event", "eventID", e.ID, "err", err)
 	}
 }
 
-func (c *cachedClient) FetchProjects(ctx context.Context, eventID string) ([]*Project, error) {
-	c.mu.Lock()
-	e := c.events[eventID]
-	if e != nil {
-		e.LastRequested = time.Now()
+// maxBoost caps how much more often a popular event is refreshed.
+const maxBoost = 3
+
+// devpostBudget is the scheduler budget shared by all the tasks, since the
+// client throttles the requests to all the devpost.com hosts together.
+const devpostBudget = "devpost"
+
+// plan schedules the background refreshes of the event. Events that were not
+// requested recently are not refreshed; their queued tasks are dropped when
+// they run. c.mu must be held.
+func (c *cachedClient) plan(e *Event) {
+	if !c.active(e) {
+		return
 	}
-	c.mu.Unlock()
-	if e != nil && time.Since(e.LastRefresh) < c.freshness {
-		return e.Projects, nil
+	now := time.Now()
+	w := e.weight(now)
+	interval := c.interval(e.ID, w)
+	if e.Info != nil {
+		c.sched.Schedule(ScheduledTask{Kind: TaskInfo, EventID: e.ID, Host: devpostBudget, Due: e.Info.LastRefresh.Add(interval), Weight: w})
+	}
+	c.sched.Schedule(ScheduledTask{Kind: TaskProjects, EventID: e.ID, Host: devpostBudget, Due: e.LastRefresh.Add(interval), Weight: w})
+	for _, p := range e.Projects {
+		c.planProject(e, p, w, interval)
 	}
-	return c.fetchProjects(ctx, eventID)
 }
 
-func (c *cachedClient) fetchProjects(ctx context.Context, eventID string) ([]*Project, error) {
-	projects, err := c.d.FetchProjects(ctx, eventID)
-	if err != nil {
-		return nil, err
+// planProject schedules the background refresh of one project. c.mu must be
+// held.
+func (c *cachedClient) planProject(e *Event, p *Project, w float64, interval time.Duration) {
+	c.sched.Schedule(ScheduledTask{Kind: TaskProject, EventID: e.ID, ProjectID: p.ID, Host: devpostBudget, Due: p.LastRefresh.Add(interval), Weight: w})
+}
+
+// touch records a request for the event and reschedules its refreshes
+// accordingly. It returns the new snapshot. c.mu must be held.
+func (c *cachedClient) touch(eventID string) *Event {
+	e := c.update(eventID, func(e *Event) { e.touch(time.Now()) })
+	c.plan(e)
+	return e
+}
+
+// active returns true if the event should be refreshed in the background.
+// c.mu must be held.
+func (c *cachedClient) active(e *Event) bool {
+	s := c.settings.Load()
+	return s.Events[e.ID].Pinned || e.LastRequested.IsZero() || time.Since(e.LastRequested) <= s.StopRefresh
+}
+
+// interval returns the refresh interval for an event of weight w.
+func (c *cachedClient) interval(eventID string, w float64) time.Duration {
+	s := c.settings.Load()
+	d := s.AutoRefresh
+	if o := s.Events[eventID].AutoRefresh; o > 0 {
+		d = o
 	}
+	return time.Duration(float64(d) / (1 + min(w, maxBoost)))
+}
 
+// runTask is called by the scheduler to refresh in the background.
+func (c *cachedClient) runTask(ctx context.Context, t *ScheduledTask) error {
+	var p *Project
 	c.mu.Lock()
-	defer c.mu.Unlock()
-	e := c.events[eventID]
-	if e != nil {
-		// There's an existing list of projects. Merge the new list into it.
-		// Create a map of old projects for efficient lookup.
-		oldProjects := make(map[string]*Project, len(e.Projects))
-		for _, p := range e.Projects {
-			oldProjects[p.ID] = p
+	e := c.events[t.EventID]
+	ok := e != nil && c.active(e)
+	if ok && t.Kind == TaskProject {
+		if i := slices.IndexFunc(e.Projects, func(p *Project) bool { return p.ID == t.ProjectID }); i != -1 {
+			p = e.Projects[i]
 		}
-		// Update the project details.
-		for _, p := range projects {
-			if old, ok := oldProjects[p.ID]; ok {
-				// Copy over the fields that are not fetched by fetchProjects.
-				p.Description = old.Description
-				p.DescriptionMD = old.DescriptionMD
-				p.Tags = old.Tags
-				p.LastRefresh = old.LastRefresh
-			}
+		ok = p != nil
+	}
+	c.mu.Unlock()
+	if !ok {
+		if f, isForgetter := c.d.(pageForgetter); isForgetter && e != nil && t.Kind == TaskProjects {
+			// The event is not refreshed anymore, don't keep its pages around.
+			f.forgetEvent(t.EventID)
 		}
-	} else {
-		e = &Event{ID: eventID}
-		c.events[eventID] = e
-		// This is the first time this event is fetched, so we are in the context
-		// of a user request.
-		e.LastRequested = time.Now()
-	}
-	e.Projects = projects
-	e.LastRefresh = time.Now()
-	return projects, nil
-}
-
-func (c *cachedClient) FetchProject(ctx context.Context, project *Project) error {
-	if time.Since(project.LastRefresh) < c.freshness {
 		return nil
 	}
-	return c.fetchProject(ctx, project)
-}
-
-func (c *cachedClient) fetchProject(ctx context.Context, project *Project) error {
-	err := c.d.FetchProject(ctx, project)
-	if err == nil {
-		project.LastRefresh = time.Now()
+	var err error
+	switch t.Kind {
+	case TaskInfo:
+		slog.InfoContext(ctx, "devpost", "msg", "auto-refreshing event info", "eventID", t.EventID)
+		_, err = c.refreshEvent(ctx, t.EventID)
+	case TaskProjects:
+		slog.InfoContext(ctx, "devpost", "msg", "auto-refreshing event", "eventID", t.EventID)
+		_, err = c.refreshProjects(ctx, t.EventID)
+	case TaskProject:
+		slog.InfoContext(ctx, "devpost", "msg", "auto-refreshing project", "projectID", t.ProjectID)
+		_, err = c.refreshProject(ctx, t.EventID, p)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "devpost", "msg", "failed to auto-refresh", "kind", t.Kind, "eventID", t.EventID, "projectID", t.ProjectID, "err", err)
 	}
 	return err
 }
 
-//
-
-type serializedCache struct {
-	Version int               `json:"version"`
-	Events  map[string]*Event `json:"events"`
-}
-
-func parseProjects(r io.Reader) ([]*Project, error) {
-	doc, err := html.Parse(r)
-	if err != nil {
-		return nil, err
+func (c *cachedClient) FetchEvent(ctx context.Context, eventID string) (*EventInfo, error) {
+	c.mu.Lock()
+	var info *EventInfo
+	if c.events[eventID] != nil {
+		info = c.touch(eventID).Info
 	}
+	c.mu.Unlock()
+	if info == nil {
+		info, err := c.refreshEvent(ctx, eventID)
+		if err != nil {
+			return nil, err
+		}
+		return info.clone(), nil
+	}
+	if time.Since(info.LastRefresh) >= c.settings.Load().Freshness {
+		c.revalidate(eventID, "info", func() error {
+			_, err := c.refreshEvent(c.ctx, eventID)
+			return err
+		})
+	}
+	return info.clone(), nil
+}
+
+// refreshEvent fetches the event info, coalescing the concurrent calls.
+func (c *cachedClient) refreshEvent(ctx context.Context, eventID string) (*EventInfo, error) {
+	return c.infoFlight.do(ctx, eventID, func() (*EventInfo, error) {
+		return c.fetchEvent(c.ctx, eventID)
+	})
+}
+
+// revalidate refreshes stale data in the background.
+func (c *cachedClient) revalidate(id, what string, refresh func() error) {
+	c.revalidating.Add(1)
+	go func() {
+		defer c.revalidating.Done()
+		if err := refresh(); err != nil {
+			slog.ErrorContext(c.ctx, "devpost", "msg", "failed to revalidate", "id", id, "what", what, "err", err)
+		}
+	}()
+}
+
+func (c *cachedClient) fetchEvent(ctx context.Context, eventID string) (*EventInfo, error) {
+	info, err := c.d.FetchEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	info.LastRefresh = time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.update(eventID, func(e *Event) {
+		if e.LastRequested.IsZero() {
+			e.touch(time.Now())
+		}
+		e.Info = info
+	})
+	c.putEvent(e)
+	c.plan(e)
+	return info, nil
+}
+
+func (c *cachedClient) FetchPrizes(ctx context.Context, eventID string) ([]Prize, error) {
+	c.mu.Lock()
+	var prizes []Prize
+	var last time.Time
+	if c.events[eventID] != nil {
+		e := c.touch(eventID)
+		prizes = e.Prizes
+		last = e.LastPrizesRefresh
+	}
+	c.mu.Unlock()
+	if last.IsZero() {
+		prizes, err := c.prizesFlight.do(ctx, eventID, func() ([]Prize, error) {
+			return c.fetchPrizes(c.ctx, eventID)
+		})
+		return slices.Clone(prizes), err
+	}
+	if time.Since(last) >= c.settings.Load().Freshness {
+		c.revalidate(eventID, "prizes", func() error {
+			_, err := c.prizesFlight.do(c.ctx, eventID, func() ([]Prize, error) {
+				return c.fetchPrizes(c.ctx, eventID)
+			})
+			return err
+		})
+	}
+	return slices.Clone(prizes), nil
+}
+
+func (c *cachedClient) fetchPrizes(ctx context.Context, eventID string) ([]Prize, error) {
+	prizes, err := c.d.FetchPrizes(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.update(eventID, func(e *Event) {
+		if e.LastRequested.IsZero() {
+			e.touch(time.Now())
+		}
+		e.Prizes = prizes
+		e.LastPrizesRefresh = time.Now()
+	})
+	c.putEvent(e)
+	c.plan(e)
+	return prizes, nil
+}
+
+// FetchProjects returns a copy of the cached projects.
+//
+// Stale projects are returned immediately while they are refreshed in the
+// background.
+func (c *cachedClient) FetchProjects(ctx context.Context, eventID string) ([]*Project, error) {
+	c.mu.Lock()
+	var e *Event
+	if c.events[eventID] != nil {
+		e = c.touch(eventID)
+	}
+	c.mu.Unlock()
+	if e == nil || e.LastRefresh.IsZero() {
+		projects, err := c.refreshProjects(ctx, eventID)
+		return cloneProjects(projects), err
+	}
+	if time.Since(e.LastRefresh) >= c.settings.Load().Freshness {
+		c.revalidate(eventID, "projects", func() error {
+			_, err := c.refreshProjects(c.ctx, eventID)
+			return err
+		})
+	}
+	return cloneProjects(e.Projects), nil
+}
+
+// refreshProjects fetches the projects, coalescing the concurrent calls. The
+// returned slice is shared.
+func (c *cachedClient) refreshProjects(ctx context.Context, eventID string) ([]*Project, error) {
+	return c.projectsFlight.do(ctx, eventID, func() ([]*Project, error) {
+		return c.fetchProjects(c.ctx, eventID)
+	})
+}
+
+// pageForgetter is implemented by clients that cache the pages of the events.
+type pageForgetter interface {
+	forgetEvent(eventID string)
+}
+
+// galleryFetcher is implemented by clients that can tell how the projects
+// were listed and whether they changed without comparing them.
+type galleryFetcher interface {
+	fetchGallery(ctx context.Context, eventID string) (*gallery, error)
+}
+
+func (c *cachedClient) fetchProjects(ctx context.Context, eventID string) ([]*Project, error) {
+	var g *gallery
+	if f, ok := c.d.(galleryFetcher); ok {
+		var err error
+		if g, err = f.fetchGallery(ctx, eventID); err != nil {
+			return nil, err
+		}
+	} else {
+		projects, err := c.d.FetchProjects(ctx, eventID)
+		if err != nil {
+			return nil, err
+		}
+		g = &gallery{projects: projects}
+	}
+	projects := g.projects
+	hash := g.hash
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if g.check != nil {
+		check := *g.check
+		if old := c.events[eventID]; old != nil && len(old.Projects) != 0 && check.Score < minScore {
+			// Keep the good data.
+			check.Rejected = true
+			c.putEvent(c.update(eventID, func(e *Event) { e.Check = &check }))
+			return nil, fmt.Errorf("%w for event %s: %s", ErrBrokenParse, eventID, strings.Join(check.Warnings, ", "))
+		}
+		// It is stored along the projects below.
+		c.update(eventID, func(e *Event) { e.Check = &check })
+	}
+	if old := c.events[eventID]; old != nil && !old.LastRefresh.IsZero() && hash != "" && hash == old.GalleryHash {
+		// The gallery is unchanged, skip the merge.
+		e := c.update(eventID, func(e *Event) {
+			e.Projects = updateVelocity(e.History, e.Projects, now)
+			e.LastRefresh = now
+		})
+		c.putEvent(e)
+		c.plan(e)
+		return e.Projects, nil
+	}
+	e := c.update(eventID, func(e *Event) {
+		if e.LastRequested.IsZero() {
+			// This is the first time this event is fetched, so we are in the
+			// context of a user request.
+			e.touch(now)
+		}
+		// Merge the new list into the existing one. The projects are new values
+		// so they can be modified.
+		oldProjects := make(map[string]*Project, len(e.Projects))
+		for _, p := range e.Projects {
+			oldProjects[p.ID] = p
+		}
+		for _, p := range projects {
+			if old, ok := oldProjects[p.ID]; ok {
+				// Copy over the fields that are not fetched by fetchProjects.
+				p.copyDetails(old)
+				p.Team = mergeTeam(p.Team, old.Team)
+			}
+		}
+		if !e.LastRefresh.IsZero() {
+			c.addChanges(e, diffProjects(eventID, e.Projects, projects, now))
+		}
+		e.History = recordHistory(e.History, projects, now)
+		e.Projects = projects
+		e.GalleryHash = hash
+		e.Discovery = g.discovery
+		e.LastRefresh = now
+	})
+	c.putEvent(e)
+	c.plan(e)
+	return projects, nil
+}
+
+// FetchProject returns the cached project details, refreshing them if stale.
+//
+// The project is looked up in project.EventID if set, otherwise in the first
+// event it is part of by event ID.
+func (c *cachedClient) FetchProject(ctx context.Context, project *Project) (*Project, error) {
+	c.mu.Lock()
+	eventID, cur := c.findProject(project.EventID, project.ID)
+	c.mu.Unlock()
+	if cur == nil {
+		eventID, cur = project.EventID, project
+	}
+	if cur.LastRefresh.IsZero() {
+		p, err := c.refreshProject(ctx, eventID, cur)
+		if err != nil {
+			return nil, err
+		}
+		return p.clone(), nil
+	}
+	if time.Since(cur.LastRefresh) >= c.settings.Load().Freshness {
+		c.revalidate(eventID+"/"+cur.ID, "project", func() error {
+			_, err := c.refreshProject(c.ctx, eventID, cur)
+			return err
+		})
+	}
+	return cur.clone(), nil
+}
+
+// refreshProject fetches the project page, coalescing the concurrent calls.
+func (c *cachedClient) refreshProject(ctx context.Context, eventID string, project *Project) (*Project, error) {
+	return c.projectFlight.do(ctx, eventID+"/"+project.ID, func() (*Project, error) {
+		return c.fetchProject(c.ctx, eventID, project)
+	})
+}
+
+// findProject returns the current snapshot of the project in the event, or
+// in the first event it is part of by event ID if eventID is empty. c.mu must
+// be held.
+func (c *cachedClient) findProject(eventID, projectID string) (string, *Project) {
+	for _, id := range slices.Sorted(maps.Keys(c.events)) {
+		if eventID != "" && id != eventID {
+			continue
+		}
+		e := c.events[id]
+		if i := slices.IndexFunc(e.Projects, func(p *Project) bool { return p.ID == projectID }); i != -1 {
+			return id, e.Projects[i]
+		}
+	}
+	return "", nil
+}
+
+// fetchProject fetches the project page and swaps the updated project in every
+// event it is part of. It returns the project as in eventID, or in the first
+// event it is part of by event ID if eventID is empty.
+func (c *cachedClient) fetchProject(ctx context.Context, eventID string, project *Project) (*Project, error) {
+	fetched, err := c.d.FetchProject(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	fetched.LastRefresh = time.Now()
+
+	var out *Project
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range slices.Sorted(maps.Keys(c.events)) {
+		old := c.events[id]
+		i := slices.IndexFunc(old.Projects, func(p *Project) bool { return p.ID == fetched.ID })
+		if i == -1 {
+			continue
+		}
+		// The gallery fields may have been refreshed while the page was being
+		// fetched, so only take the details from fetched.
+		before := old.Projects[i]
+		p := *before
+		p.copyDetails(fetched)
+		p.keepEvent(id)
+		p.Team = fetched.Team
+		// The comments deleted since are only known when the page lists as many
+		// comments as the gallery counts. p has the latest gallery count.
+		full := len(fetched.Comments) >= p.CommentCount
+		p.Comments = mergeComments(before.Comments, fetched.Comments, full)
+		p.Updates = mergeComments(before.Updates, fetched.Updates, false)
+		e := c.update(id, func(e *Event) {
+			e.Projects = slices.Clone(e.Projects)
+			e.Projects[i] = &p
+			// Skip the initial load of the project details.
+			if !before.LastRefresh.IsZero() {
+				c.addChanges(e, diffProject(id, before, &p, p.LastRefresh))
+			}
+		})
+		if err := c.store.PutProject(id, &p); err != nil {
+			slog.ErrorContext(ctx, "devpost", "msg", "failed to store project", "projectID", p.ID, "err", err)
+		}
+		if c.active(e) {
+			w := e.weight(time.Now())
+			c.planProject(e, &p, w, c.interval(e.ID, w))
+		}
+		if out == nil || id == eventID {
+			out = &p
+		}
+	}
+	if out == nil {
+		return fetched, nil
+	}
+	return out, nil
+}
+
+func (c *cachedClient) FetchHistory(ctx context.Context, eventID, projectID string) ([]Sample, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.events[eventID]
+	if e == nil {
+		return nil, nil
+	}
+	return slices.Clone(e.History[projectID]), nil
+}
+
+func (c *cachedClient) FetchChanges(ctx context.Context, eventID string, since int64) ([]Change, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.events[eventID]
+	if e == nil {
+		return nil, nil
+	}
+	return e.changesSince(since), nil
+}
+
+func (c *cachedClient) RefreshQueue(ctx context.Context) ([]ScheduledTask, error) {
+	return c.sched.Queue(), nil
+}
+
+func (c *cachedClient) Upstream(ctx context.Context) (*UpstreamStatus, error) {
+	return c.d.Upstream(ctx)
+}
+
+func (c *cachedClient) ScraperHealth(ctx context.Context) (*ScraperHealth, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := &ScraperHealth{Healthy: true, Events: []ScrapeCheck{}}
+	for _, e := range c.events {
+		if e.Check != nil {
+			out.Events = append(out.Events, *e.Check)
+			out.Healthy = out.Healthy && e.Check.Healthy()
+		}
+	}
+	slices.SortFunc(out.Events, func(a, b ScrapeCheck) int { return strings.Compare(a.EventID, b.EventID) })
+	return out, nil
+}
+
+// FetchPeople returns the participants of the event, fetching its projects
+// if needed.
+func (c *cachedClient) FetchPeople(ctx context.Context, eventID string) ([]*Participant, error) {
+	if _, err := c.FetchProjects(ctx, eventID); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.people.event(eventID), nil
+}
+
+const (
+	// maxProfiles bounds the number of cached profiles.
+	maxProfiles = 1000
+	// profileTTL is how long a profile is kept when it is not requested.
+	profileTTL = 24 * time.Hour
+)
+
+// profileEntry is a cached profile page.
+type profileEntry struct {
+	// p is nil if the profile page couldn't be fetched.
+	p       *Participant
+	fetched time.Time
+}
+
+// FetchPerson returns the person's projects in the cached events and their
+// profile. Stale profiles are returned immediately while they are refreshed
+// in the background.
+//
+// A person that is not in a team of the cached events is not found.
+func (c *cachedClient) FetchPerson(ctx context.Context, username string) (*Participant, error) {
+	c.mu.Lock()
+	out := c.people.person(username)
+	entry, ok := c.profiles[strings.ToLower(username)]
+	c.mu.Unlock()
+	if out == nil {
+		// Only the participants of the cached events are looked up, so
+		// arbitrary paths are not fetched from devpost.
+		return nil, &HTTPError{StatusCode: http.StatusNotFound, Body: []byte("unknown person")}
+	}
+	if !ok || time.Since(entry.fetched) >= profileTTL {
+		var err error
+		if entry, err = c.refreshProfile(ctx, username); err != nil {
+			slog.WarnContext(ctx, "devpost", "msg", "failed to fetch profile", "person", username, "err", err)
+		}
+	} else if time.Since(entry.fetched) >= c.settings.Load().Freshness {
+		c.revalidate(username, "profile", func() error {
+			_, err := c.refreshProfile(c.ctx, username)
+			return err
+		})
+	}
+	if entry.p != nil {
+		out.Profile = entry.p.Profile
+	}
+	return out, nil
+}
+
+// refreshProfile fetches the profile page, coalescing the concurrent calls. A
+// missing profile page is cached too.
+func (c *cachedClient) refreshProfile(ctx context.Context, username string) (profileEntry, error) {
+	p, err := c.profileFlight.do(ctx, strings.ToLower(username), func() (*Participant, error) {
+		p, err := c.d.FetchPerson(c.ctx, username)
+		var herr *HTTPError
+		if err != nil && (!errors.As(err, &herr) || herr.StatusCode != http.StatusNotFound) {
+			return nil, err
+		}
+		c.mu.Lock()
+		c.putProfile(strings.ToLower(username), profileEntry{p: p, fetched: time.Now()})
+		c.mu.Unlock()
+		return p, err
+	})
+	return profileEntry{p: p}, err
+}
+
+// putProfile caches the profile, dropping the expired ones and the oldest
+// ones past maxProfiles. c.mu must be held.
+func (c *cachedClient) putProfile(key string, entry profileEntry) {
+	maps.DeleteFunc(c.profiles, func(_ string, e profileEntry) bool {
+		return entry.fetched.Sub(e.fetched) >= profileTTL
+	})
+	delete(c.profiles, key)
+	for len(c.profiles) >= maxProfiles {
+		oldest := ""
+		for k, e := range c.profiles {
+			if oldest == "" || e.fetched.Before(c.profiles[oldest].fetched) {
+				oldest = k
+			}
+		}
+		delete(c.profiles, oldest)
+	}
+	c.profiles[key] = entry
+}
+
+// ListEvents returns the cached listing. Stale listings are returned
+// immediately while they are refreshed in the background.
+func (c *cachedClient) ListEvents(ctx context.Context, q EventQuery) ([]Hackathon, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	key := q.url()
+	c.mu.Lock()
+	entry, ok := c.listings[key]
+	c.mu.Unlock()
+	if !ok {
+		hackathons, err := c.refreshListing(ctx, key, q)
+		return slices.Clone(hackathons), err
+	}
+	if time.Since(entry.fetched) >= c.settings.Load().Freshness {
+		c.revalidate(key, "hackathons", func() error {
+			_, err := c.refreshListing(c.ctx, key, q)
+			return err
+		})
+	}
+	return slices.Clone(entry.hackathons), nil
+}
+
+// refreshListing fetches a listing page, coalescing the concurrent calls. The
+// returned slice is shared.
+func (c *cachedClient) refreshListing(ctx context.Context, key string, q EventQuery) ([]Hackathon, error) {
+	return c.listingFlight.do(ctx, key, func() ([]Hackathon, error) {
+		hackathons, err := c.d.ListEvents(c.ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.listings[key] = listingEntry{hackathons: hackathons, fetched: now}
+		// Arbitrary searches must not grow the cache forever.
+		for len(c.listings) > maxListings {
+			oldest := ""
+			for k, e := range c.listings {
+				if oldest == "" || e.fetched.Before(c.listings[oldest].fetched) {
+					oldest = k
+				}
+			}
+			delete(c.listings, oldest)
+		}
+		return hackathons, nil
+	})
+}
+
+func (c *cachedClient) WaitChanges(ctx context.Context, eventID string, since int64) ([]Change, error) {
+	for {
+		var out []Change
+		c.mu.Lock()
+		if e := c.events[eventID]; e != nil {
+			out = e.changesSince(since)
+		}
+		changed := c.changed
+		c.mu.Unlock()
+		if len(out) != 0 {
+			return out, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-changed:
+		}
+	}
+}
+
+// addChanges records the changes in e, which must not be published yet, and
+// wakes up the waiters.
+//
+// c.mu must be held.
+func (c *cachedClient) addChanges(e *Event, changes []Change) {
+	if len(changes) == 0 {
+		return
+	}
+	e.addChanges(changes)
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+//
+
+// parseProjects returns the projects of the gallery and how well they parsed.
+func parseProjects(doc *html.Node) ([]*Project, pageCheck) {
+	check := pageCheck{softwareLinks: countSoftwareLinks(doc)}
 	galleryNode := dom.FirstChild(doc, dom.Tag("div"), dom.ID("submission-gallery"))
 	if galleryNode == nil {
 		// No gallery found on this page, which is the end of pagination
-		return nil, nil
+		return nil, check
 	}
 	var projects []*Project
 	for c := range dom.YieldChildren(galleryNode, dom.Tag("div"), dom.Class("gallery-item")) {
-		p := parseProjectNode(c)
+		p, badCounts := parseProjectNode(c)
+		check.checkProject(&p)
+		if badCounts {
+			check.badCounts++
+		}
 		projects = append(projects, &p)
 	}
-	return projects, nil
+	return projects, check
 }
 
-func parseProjectNode(n *html.Node) Project {
-	p := Project{}
+// parseProjectNode parses a gallery card. badCounts is true if the likes or
+// comments count couldn't be parsed.
+func parseProjectNode(n *html.Node) (p Project, badCounts bool) {
 	p.ID = dom.NodeAttr(n, "data-software-id")
 	if linkNode := dom.FirstChild(n, dom.Tag("a"), dom.Class("block-wrapper-link")); linkNode != nil {
 		p.URL = dom.NodeAttr(linkNode, "href")
@@ -481,11 +1618,377 @@ func parseProjectNode(n *html.Node) Project {
 		t, err := strconv.Atoi(dom.NodeText(likeNode))
 		if err != nil {
 			slog.Error("failed to parse like count", "project", p.ID, "err", err)
+			badCounts = true
 		}
 		p.Likes = t
 	}
+	if commentNode := dom.FirstChild(n, dom.Tag("span"), dom.Class("count"), dom.Class("comment-count")); commentNode != nil {
+		t, err := strconv.Atoi(dom.NodeText(commentNode))
+		if err != nil {
+			slog.Error("failed to parse comment count", "project", p.ID, "err", err)
+			badCounts = true
+		}
+		p.CommentCount = t
+	}
 	// Description is not directly available on the nroject card.
-	return p
+	return p, badCounts
+}
+
+// samePerson returns true if both devpost profile URLs point to the same
+// person.
+func samePerson(a, b string) bool {
+	return a != "" && strings.EqualFold(strings.TrimSuffix(a, "/"), strings.TrimSuffix(b, "/"))
+}
+
+// addMembers sets the roles of the members already in team and appends the
+// others.
+func addMembers(team, members []Person) []Person {
+	for _, m := range members {
+		i := slices.IndexFunc(team, func(p Person) bool { return samePerson(p.URL, m.URL) })
+		if i == -1 {
+			team = append(team, m)
+		} else {
+			team[i].Role = m.Role
+		}
+	}
+	return team
+}
+
+// mergeComments returns old plus the comments of fetched that are not in old
+// yet, oldest first. The comments of old are updated in case they were edited.
+// Comments missing from fetched are kept, since devpost only lists the latest
+// ones, unless full is set meaning fetched is the whole thread. Otherwise old
+// is kept when fetched is empty in case the comments failed to parse.
+func mergeComments(old, fetched []Comment, full bool) []Comment {
+	if len(fetched) == 0 && !full {
+		return old
+	}
+	out := slices.Clone(old)
+	if full {
+		out = nil
+	}
+	seen := make(map[string]int, len(old)+len(fetched))
+	for i := range out {
+		seen[out[i].key()] = i
+	}
+	for _, c := range fetched {
+		k := c.key()
+		if i, ok := seen[k]; ok {
+			out[i] = c
+			continue
+		}
+		seen[k] = len(out)
+		out = append(out, c)
+	}
+	slices.SortStableFunc(out, func(a, b Comment) int { return a.Time.Compare(b.Time) })
+	return out
+}
+
+// mergeTeam copies the roles and the members missing from the gallery from
+// the old team, since they are only available on the project page. The
+// members that left are dropped when the project page is fetched again.
+func mergeTeam(team, old []Person) []Person {
+	for _, o := range old {
+		i := slices.IndexFunc(team, func(p Person) bool { return samePerson(p.URL, o.URL) })
+		if i == -1 {
+			team = append(team, o)
+		} else {
+			team[i].Role = o.Role
+		}
+	}
+	return team
+}
+
+// normalizeURL makes protocol relative URLs absolute. It returns an empty
+// string for non-http links.
+func normalizeURL(u string) string {
+	u = strings.TrimSpace(u)
+	if strings.HasPrefix(u, "//") {
+		u = "https:" + u
+	}
+	if !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+		return ""
+	}
+	return u
+}
+
+// classifyLink guesses the kind of link based on its host and path.
+func classifyLink(rawURL string) LinkKind {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return LinkOther
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	p := strings.ToLower(u.Path)
+	switch host {
+	case "github.com", "gitlab.com", "bitbucket.org", "codeberg.org", "sr.ht", "git.sr.ht":
+		return LinkRepo
+	case "youtube.com", "youtu.be", "m.youtube.com", "youtube-nocookie.com", "vimeo.com", "player.vimeo.com", "loom.com":
+		return LinkVideo
+	case "slides.com", "speakerdeck.com", "pitch.com", "slideshare.net":
+		return LinkSlides
+	case "docs.google.com":
+		if strings.HasPrefix(p, "/presentation/") {
+			return LinkSlides
+		}
+		return LinkOther
+	case "devpost.com", "drive.google.com", "medium.com", "linkedin.com", "twitter.com", "x.com", "notion.so", "figma.com":
+		return LinkOther
+	}
+	if strings.HasSuffix(p, ".pdf") || strings.HasSuffix(p, ".pptx") || strings.HasSuffix(host, ".canva.com") || host == "canva.com" {
+		return LinkSlides
+	}
+	// Anything else listed under "Try it out" is most likely a live demo.
+	return LinkDemo
+}
+
+var reQuantity = regexp.MustCompile(`(\d+)\s+winners?\b`)
+
+func parsePrizes(r io.Reader) ([]Prize, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	var prizes []Prize
+	for n := range dom.YieldChildren(doc, dom.Tag("div"), dom.Class("prize")) {
+		p := Prize{Quantity: 1}
+		if h := dom.FirstChild(n, isHeading); h != nil {
+			p.Name = dom.NodeText(h)
+		}
+		if p.Name == "" {
+			continue
+		}
+		var desc []string
+		for c := range dom.YieldChildren(n, dom.Tag("p")) {
+			// The sponsor has its own field.
+			if t := dom.NodeText(c); t != "" && !dom.Class("sponsor")(c) && !strings.HasPrefix(t, "Sponsored by ") {
+				desc = append(desc, t)
+			}
+		}
+		p.Description = strings.Join(desc, "\n")
+		if v := dom.FirstChild(n, dom.Class("prize-value")); v != nil {
+			p.Value = dom.NodeText(v)
+		} else if m := reAmount.FindString(p.Description); m != "" {
+			p.Value = m
+		}
+		text := dom.NodeText(n)
+		if m := reQuantity.FindStringSubmatch(text); m != nil {
+			p.Quantity, _ = strconv.Atoi(m[1])
+		}
+		if s := dom.FirstChild(n, dom.Class("sponsor")); s != nil {
+			p.Sponsor = dom.NodeText(s)
+		} else if _, after, ok := strings.Cut(text, "Sponsored by "); ok {
+			p.Sponsor = strings.TrimSpace(after)
+		}
+		prizes = append(prizes, p)
+	}
+	return prizes, nil
+}
+
+var (
+	reAmount       = regexp.MustCompile(`\p{Sc}\s?[\d,]+(?:\.\d+)?`)
+	reParticipants = regexp.MustCompile(`([\d,]+)\s+participants?\b`)
+	rePrizeTotal   = regexp.MustCompile(`(\p{Sc}\s?[\d,]+(?:\.\d+)?)\s+in\s+(?:cash\s+)?prizes`)
+)
+
+func parseEventInfo(r io.Reader) (*EventInfo, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	info := &EventInfo{
+		Title:     metaContent(doc, "og:title"),
+		Tagline:   metaContent(doc, "og:description"),
+		BannerURL: metaContent(doc, "og:image"),
+	}
+	if header := dom.FirstChild(doc, dom.ID("challenge-header")); header != nil {
+		if n := dom.FirstChild(header, dom.Tag("h1")); n != nil {
+			info.Title = dom.NodeText(n)
+		}
+		if n := dom.FirstChild(header, dom.Tag("h3")); n != nil {
+			info.Tagline = dom.NodeText(n)
+		}
+	}
+	if info.Title == "" {
+		if n := dom.FirstChild(doc, dom.Tag("title")); n != nil {
+			info.Title = dom.NodeText(n)
+		}
+	}
+	// Devpost embeds a schema.org Event, which is the most reliable source for
+	// the dates and location.
+	for n := range dom.YieldChildren(doc, dom.Tag("script"), dom.Attr("type", "application/ld+json")) {
+		if parseEventLD(dom.NodeText(n), info) {
+			break
+		}
+	}
+	if info.StartsAt.IsZero() {
+		if n := dom.FirstChild(doc, dom.Class("submission-period")); n != nil {
+			info.StartsAt, info.EndsAt = parseSubmissionPeriod(dom.NodeText(n))
+		}
+	}
+	for n := range dom.YieldChildren(doc, dom.Tag("time")) {
+		if isDeadline(n) {
+			if t, err2 := time.Parse(time.RFC3339, dom.NodeAttr(n, "datetime")); err2 == nil {
+				info.Deadline = t
+				break
+			}
+		}
+	}
+	if info.Deadline.IsZero() {
+		info.Deadline = info.EndsAt
+	}
+	if info.Location == "" {
+		for n := range dom.YieldChildren(doc, dom.Class("info-with-icon")) {
+			if dom.FirstChild(n, dom.Tag("i"), dom.Class("fa-globe")) != nil || dom.FirstChild(n, dom.Tag("i"), dom.Class("fa-map-marker-alt")) != nil {
+				info.Location = dom.NodeText(n)
+				break
+			}
+		}
+	}
+	if n := dom.FirstChild(doc, dom.ID("challenges")); n != nil {
+		for c := range dom.YieldChildren(n, dom.Class("challenge")) {
+			if h := dom.FirstChild(c, isHeading); h != nil {
+				info.Challenges = append(info.Challenges, dom.NodeText(h))
+			}
+		}
+	}
+	text := dom.NodeText(doc)
+	if m := rePrizeTotal.FindStringSubmatch(text); m != nil {
+		info.PrizeTotal = strings.ReplaceAll(m[1], " ", "")
+		info.PrizeAmount = parseAmount(info.PrizeTotal)
+	}
+	if m := reParticipants.FindStringSubmatch(text); m != nil {
+		info.Participants, _ = strconv.Atoi(strings.ReplaceAll(m[1], ",", ""))
+	}
+	return info, nil
+}
+
+// isHeading selects <h1> to <h6> nodes.
+func isHeading(n *html.Node) bool {
+	return n.Type == html.ElementNode && len(n.Data) == 2 && n.Data[0] == 'h' && n.Data[1] >= '1' && n.Data[1] <= '6'
+}
+
+// metaContent returns the content of a <meta> tag by property or name.
+func metaContent(doc *html.Node, key string) string {
+	if n := dom.FirstChild(doc, dom.Tag("meta"), dom.Attr("property", key)); n != nil {
+		return strings.TrimSpace(dom.NodeAttr(n, "content"))
+	}
+	if n := dom.FirstChild(doc, dom.Tag("meta"), dom.Attr("name", key)); n != nil {
+		return strings.TrimSpace(dom.NodeAttr(n, "content"))
+	}
+	return ""
+}
+
+// parseEventLD fills info from a JSON-LD schema.org Event. Returns false if
+// the document is not an event.
+func parseEventLD(s string, info *EventInfo) bool {
+	var ld struct {
+		Type        string          `json:"@type"`
+		Name        string          `json:"name"`
+		Description string          `json:"description"`
+		StartDate   string          `json:"startDate"`
+		EndDate     string          `json:"endDate"`
+		Location    json.RawMessage `json:"location"`
+	}
+	if err := json.Unmarshal([]byte(s), &ld); err != nil || ld.Type != "Event" {
+		return false
+	}
+	if info.Title == "" {
+		info.Title = ld.Name
+	}
+	if info.Tagline == "" {
+		info.Tagline = ld.Description
+	}
+	info.StartsAt = parseDate(ld.StartDate)
+	info.EndsAt = parseDate(ld.EndDate)
+	var loc struct {
+		Type string `json:"@type"`
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(ld.Location, &loc); err == nil {
+		if loc.Name != "" {
+			info.Location = loc.Name
+		} else if loc.Type == "VirtualLocation" {
+			info.Location = "Online"
+		}
+	} else {
+		_ = json.Unmarshal(ld.Location, &info.Location)
+	}
+	return true
+}
+
+func parseDate(s string) time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// parseSubmissionPeriod parses the human readable period, e.g.
+// "Jun 20 - Jul 31, 2025".
+func parseSubmissionPeriod(s string) (time.Time, time.Time) {
+	s = strings.ReplaceAll(s, "–", "-")
+	before, after, ok := strings.Cut(s, "-")
+	if !ok {
+		return time.Time{}, time.Time{}
+	}
+	end, err := time.Parse("Jan 2, 2006", strings.TrimSpace(after))
+	if err != nil {
+		return time.Time{}, time.Time{}
+	}
+	before = strings.TrimSpace(before)
+	start, err := time.Parse("Jan 2, 2006", before)
+	if err != nil {
+		if start, err = time.Parse("Jan 2", before); err != nil {
+			return time.Time{}, end
+		}
+		start = start.AddDate(end.Year(), 0, 0)
+		// The period crosses a year, e.g. "Dec 20 - Jan 5, 2026".
+		if start.After(end) {
+			start = start.AddDate(-1, 0, 0)
+		}
+	}
+	return start, end
+}
+
+// eventIDFromURL returns the event ID of a hackathon URL like
+// https://vibe.devpost.com/, or "" if it is not hosted on devpost.com.
+func eventIDFromURL(s string) string {
+	u, err := url.Parse(s)
+	if err != nil {
+		return ""
+	}
+	id, _ := strings.CutSuffix(strings.ToLower(u.Hostname()), ".devpost.com")
+	if id == strings.ToLower(u.Hostname()) || strings.Contains(id, ".") {
+		return ""
+	}
+	return id
+}
+
+// isDeadline returns true if the node or one of its ancestors is labeled as
+// the submission deadline.
+func isDeadline(n *html.Node) bool {
+	for ; n != nil; n = n.Parent {
+		if strings.Contains(dom.NodeAttr(n, "class"), "deadline") || strings.Contains(dom.NodeAttr(n, "id"), "deadline") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAmount parses a formatted amount like "$10,000.00" and returns the
+// integer part.
+func parseAmount(s string) int {
+	s, _, _ = strings.Cut(s, ".")
+	v := 0
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			v = v*10 + int(r-'0')
+		}
+	}
+	return v
 }
 
 type HTTPError struct {
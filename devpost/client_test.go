@@ -0,0 +1,357 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devpost
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseEventInfo(t *testing.T) {
+	const page = `<html><head>
+<title>Fallback | Devpost</title>
+<meta property="og:title" content="Vibe Coding Hackathon">
+<meta property="og:description" content="Build with vibes">
+<meta property="og:image" content="https://example.com/banner.png">
+<script type="application/ld+json">{"@type":"Event","name":"Vibe","startDate":"2025-06-20T09:00:00-07:00","endDate":"2025-07-01T17:00:00-07:00","location":{"@type":"VirtualLocation"}}</script>
+</head><body>
+<div class="prizes"><strong>$50,000</strong> in prizes</div>
+<a href="/participants"><strong>1,234</strong> participants</a>
+<div id="challenges"><div class="challenge"><h5>Web</h5></div><div class="challenge"><h5>Mobile</h5></div></div>
+<div id="submission-deadline"><time datetime="2025-06-30T17:00:00-07:00">Jun 30</time></div>
+</body></html>`
+	info, err := parseEventInfo(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Title != "Vibe Coding Hackathon" {
+		t.Errorf("Title = %q", info.Title)
+	}
+	if info.Tagline != "Build with vibes" {
+		t.Errorf("Tagline = %q", info.Tagline)
+	}
+	if info.BannerURL != "https://example.com/banner.png" {
+		t.Errorf("BannerURL = %q", info.BannerURL)
+	}
+	if info.Location != "Online" {
+		t.Errorf("Location = %q", info.Location)
+	}
+	if info.PrizeTotal != "$50,000" || info.PrizeAmount != 50000 {
+		t.Errorf("Prize = %q %d", info.PrizeTotal, info.PrizeAmount)
+	}
+	if info.Participants != 1234 {
+		t.Errorf("Participants = %d", info.Participants)
+	}
+	if want := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC); !info.Deadline.Equal(want) {
+		t.Errorf("Deadline = %s", info.Deadline)
+	}
+	if !slices.Equal(info.Challenges, []string{"Web", "Mobile"}) {
+		t.Errorf("Challenges = %q", info.Challenges)
+	}
+	if info.StartsAt.IsZero() || info.EndsAt.IsZero() {
+		t.Errorf("Dates not parsed: %s %s", info.StartsAt, info.EndsAt)
+	}
+}
+
+func TestParseSubmissionPeriod(t *testing.T) {
+	start, end := parseSubmissionPeriod("Jun 20 – Jul 31, 2025")
+	if want := time.Date(2025, 6, 20, 0, 0, 0, 0, time.UTC); !start.Equal(want) {
+		t.Errorf("start = %s", start)
+	}
+	if want := time.Date(2025, 7, 31, 0, 0, 0, 0, time.UTC); !end.Equal(want) {
+		t.Errorf("end = %s", end)
+	}
+	start, end = parseSubmissionPeriod("Dec 20 - Jan 5, 2026")
+	if want := time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC); !start.Equal(want) {
+		t.Errorf("start = %s", start)
+	}
+	if want := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC); !end.Equal(want) {
+		t.Errorf("end = %s", end)
+	}
+}
+
+func TestParsePrizes(t *testing.T) {
+	const page = `<html><body><div id="prizes">
+<div class="prize"><h6>Grand Prize</h6><p>$10,000 in cash</p><p>2 winners</p></div>
+<div class="prize"><h6>Best Use of Gophers</h6><p>A plush gopher.</p><p class="sponsor">Go Team</p></div>
+<div class="prize"><h6>Cloud Credits</h6><p>$500 in credits</p><p>Sponsored by Acme Cloud</p></div>
+</div></body></html>`
+	prizes, err := parsePrizes(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Prize{
+		{Name: "Grand Prize", Description: "$10,000 in cash\n2 winners", Value: "$10,000", Quantity: 2},
+		{Name: "Best Use of Gophers", Description: "A plush gopher.", Quantity: 1, Sponsor: "Go Team"},
+		{Name: "Cloud Credits", Description: "$500 in credits", Value: "$500", Quantity: 1, Sponsor: "Acme Cloud"},
+	}
+	if !slices.Equal(prizes, want) {
+		t.Errorf("got %#v\nwant %#v", prizes, want)
+	}
+}
+
+func TestParseProjectPage(t *testing.T) {
+	const page = `<html><body>
+<div id="app-details-left"><p>Hello <b>world</b></p></div>
+<div id="gallery"><ul>
+<li><iframe class="video-embed" src="//www.youtube.com/embed/abc"></iframe></li>
+<li><a href="https://example.com/full.png"><img src="https://example.com/small.png"></a><p><i>The UI</i></p></li>
+</ul></div>
+<div id="app-team"><ul>
+<li class="software-team-member"><a class="user-profile-link" href="https://devpost.com/alice"><img alt="Alice" src="a.png"></a><p class="bubble">Backend</p></li>
+<li class="software-team-member"><a class="user-profile-link" href="https://devpost.com/bob"><img alt="Bob" src="b.png"></a><p class="bubble">Design</p></li>
+</ul></div>
+<nav class="app-links"><ul data-role="software-urls">
+<li><a href="https://github.com/maruel/devpostdash"><span>github.com</span></a></li>
+<li><a href="https://devpostdash.example.app/">Try it</a></li>
+<li><a href="javascript:alert(1)">Bad</a></li>
+</ul></nav>
+<div id="built-with"><span class="cp-tag">go</span><span class="cp-tag">html</span></div>
+<div id="submissions"><ul><li>
+<div class="software-list-content"><p><a href="https://vibe.devpost.com/">Vibe Hackathon</a></p>
+<ul class="no-bullet"><li><span class="winner label">Winner</span> Grand Prize</li><li>Best Use of Gophers</li></ul></div>
+</li><li>
+<div class="software-list-content"><p><a href="https://other.devpost.com/">Other Hackathon</a></p>
+<ul class="no-bullet"><li>Healthcare</li></ul></div>
+</li></ul></div>
+</body></html>`
+	p := Project{Team: []Person{{Name: "Alice", URL: "https://devpost.com/alice/"}}}
+	if err := parseProjectPage(strings.NewReader(page), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Description != "Hello world" {
+		t.Errorf("Description = %q", p.Description)
+	}
+	if !slices.Equal(p.Tags, []string{"go", "html"}) {
+		t.Errorf("Tags = %q", p.Tags)
+	}
+	if want := []Image{{URL: "https://example.com/full.png", Caption: "The UI"}}; !slices.Equal(p.Gallery, want) {
+		t.Errorf("Gallery = %#v", p.Gallery)
+	}
+	wantTeam := []Person{
+		{Name: "Alice", URL: "https://devpost.com/alice/", Role: "Backend"},
+		{Name: "Bob", URL: "https://devpost.com/bob", AvatarURL: "b.png", Role: "Design"},
+	}
+	if !slices.Equal(p.Team, wantTeam) {
+		t.Errorf("Team = %#v", p.Team)
+	}
+	wantLinks := []Link{
+		{Kind: LinkRepo, Label: "github.com", URL: "https://github.com/maruel/devpostdash"},
+		{Kind: LinkDemo, Label: "Try it", URL: "https://devpostdash.example.app/"},
+	}
+	if !slices.Equal(p.Links, wantLinks) {
+		t.Errorf("Links = %#v", p.Links)
+	}
+	if p.VideoURL != "https://www.youtube.com/embed/abc" {
+		t.Errorf("VideoURL = %q", p.VideoURL)
+	}
+	if !slices.Equal(p.Challenges, []string{"Grand Prize", "Best Use of Gophers", "Healthcare"}) {
+		t.Errorf("Challenges = %q", p.Challenges)
+	}
+	if !slices.Equal(p.Prizes, []string{"Grand Prize"}) {
+		t.Errorf("Prizes = %q", p.Prizes)
+	}
+	// Other hackathons' tracks are dropped once the event is known.
+	other := p
+	other.keepEvent("other")
+	if !slices.Equal(other.Challenges, []string{"Healthcare"}) || len(other.Prizes) != 0 {
+		t.Errorf("Challenges = %q, Prizes = %q", other.Challenges, other.Prizes)
+	}
+	p.keepEvent("vibe")
+	if !slices.Equal(p.Challenges, []string{"Grand Prize", "Best Use of Gophers"}) || !slices.Equal(p.Prizes, []string{"Grand Prize"}) {
+		t.Errorf("Challenges = %q, Prizes = %q", p.Challenges, p.Prizes)
+	}
+}
+
+func TestEventIDFromURL(t *testing.T) {
+	for in, want := range map[string]string{
+		"https://vibe.devpost.com/":      "vibe",
+		"https://Vibe.devpost.com/rules": "vibe",
+		"https://devpost.com/software/x": "",
+		"https://a.b.devpost.com/":       "",
+		"https://example.com/":           "",
+	} {
+		if got := eventIDFromURL(in); got != want {
+			t.Errorf("eventIDFromURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestParseComments(t *testing.T) {
+	const page = `<html><body>
+<div id="updates"><article class="update" id="update-7">
+<a class="user-profile-link" href="https://devpost.com/alice"><img alt="Alice" src="a.png"></a>
+<time datetime="2025-06-22T10:00:00Z">Jun 22</time>
+<div class="update-body"><p>We shipped <b>v2</b>.</p></div>
+</article></div>
+<div id="comments"><ul>
+<li class="comment" id="comment-12"><a class="user-profile-link" href="https://devpost.com/carol">Carol</a>
+<time datetime="2025-06-23T09:00:00Z">Jun 23</time><div class="comment-body"><p>Love it</p></div></li>
+<li class="comment" id="comment-11"><a class="user-profile-link" href="https://devpost.com/bob"><img alt="Bob" src="b.png"></a>
+<time datetime="2025-06-21T08:00:00Z">Jun 21</time><div class="comment-body"><p>See <a href="https://example.com">this</a></p></div></li>
+<li class="comment" id="comment-10"><div class="comment-body"></div></li>
+</ul></div>
+</body></html>`
+	var p Project
+	if err := parseProjectPage(strings.NewReader(page), &p); err != nil {
+		t.Fatal(err)
+	}
+	wantComments := []Comment{
+		{ID: "11", Author: Person{Name: "Bob", URL: "https://devpost.com/bob", AvatarURL: "b.png"}, Time: time.Date(2025, 6, 21, 8, 0, 0, 0, time.UTC), Text: "See [this](https://example.com)"},
+		{ID: "12", Author: Person{Name: "Carol", URL: "https://devpost.com/carol"}, Time: time.Date(2025, 6, 23, 9, 0, 0, 0, time.UTC), Text: "Love it"},
+	}
+	if !slices.Equal(p.Comments, wantComments) {
+		t.Errorf("Comments = %#v", p.Comments)
+	}
+	wantUpdates := []Comment{
+		{ID: "7", Author: Person{Name: "Alice", URL: "https://devpost.com/alice", AvatarURL: "a.png"}, Time: time.Date(2025, 6, 22, 10, 0, 0, 0, time.UTC), Text: "We shipped **v2**."},
+	}
+	if !slices.Equal(p.Updates, wantUpdates) {
+		t.Errorf("Updates = %#v", p.Updates)
+	}
+}
+
+func TestMergeComments(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 6, d, 0, 0, 0, 0, time.UTC) }
+	old := []Comment{
+		{ID: "1", Time: day(1), Text: "first"},
+		{Author: Person{URL: "https://devpost.com/bob"}, Time: day(2), Text: "no ID"},
+	}
+	// devpost only lists the latest comments, and the first two were edited.
+	fetched := []Comment{
+		{ID: "1", Time: day(1), Text: "first, edited"},
+		{Author: Person{URL: "https://devpost.com/bob"}, Time: day(2), Text: "no ID, edited"},
+		{ID: "3", Time: day(3), Text: "third"},
+	}
+	texts := func(comments []Comment) []string {
+		var out []string
+		for _, c := range comments {
+			out = append(out, c.Text)
+		}
+		return out
+	}
+	got := mergeComments(old, fetched, false)
+	if want := []string{"first, edited", "no ID, edited", "third"}; !slices.Equal(texts(got), want) {
+		t.Errorf("got %q, want %q", texts(got), want)
+	}
+	if got := mergeComments(got, fetched[2:], false); len(got) != 3 {
+		t.Errorf("Expected the old comments to be kept, got %d", len(got))
+	}
+	// The whole thread is listed, the missing comments were deleted.
+	if got := mergeComments(got, fetched[1:], true); !slices.Equal(texts(got), []string{"no ID, edited", "third"}) {
+		t.Errorf("Expected the deleted comment to be dropped, got %q", texts(got))
+	}
+	// All the comments were deleted.
+	if got := mergeComments(got, nil, true); len(got) != 0 {
+		t.Errorf("Expected the comments to be dropped, got %q", texts(got))
+	}
+	if old[0].Text != "first" {
+		t.Error("old was modified")
+	}
+
+	// Without ID nor time, two comments of the same author are told apart by
+	// their text.
+	bob := Person{URL: "https://devpost.com/bob"}
+	untimed := []Comment{{Author: bob, Text: "one"}, {Author: bob, Text: "two"}}
+	if got := mergeComments(untimed[:1], untimed, false); !slices.Equal(texts(got), []string{"one", "two"}) {
+		t.Errorf("got %q", texts(got))
+	}
+}
+
+func TestClassifyLink(t *testing.T) {
+	tests := []struct {
+		url  string
+		want LinkKind
+	}{
+		{"https://github.com/maruel/devpostdash", LinkRepo},
+		{"https://youtu.be/abc", LinkVideo},
+		{"https://player.vimeo.com/video/1", LinkVideo},
+		{"https://docs.google.com/presentation/d/abc", LinkSlides},
+		{"https://docs.google.com/document/d/abc", LinkOther},
+		{"https://example.com/deck.pdf", LinkSlides},
+		{"https://myapp.vercel.app", LinkDemo},
+	}
+	for _, tt := range tests {
+		if got := classifyLink(tt.url); got != tt.want {
+			t.Errorf("classifyLink(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+// galleryServer serves a gallery of pages with the given project IDs.
+type galleryServer struct {
+	pages [][]string
+	// listed is the number of pages in the pagination control of page 1.
+	listed int
+
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+}
+
+func (g *galleryServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	g.inFlight++
+	g.maxInFlight = max(g.maxInFlight, g.inFlight)
+	g.mu.Unlock()
+	defer func() {
+		g.mu.Lock()
+		g.inFlight--
+		g.mu.Unlock()
+	}()
+	// Give a chance to the other requests to start.
+	time.Sleep(10 * time.Millisecond)
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	fmt.Fprint(w, `<div id="submission-gallery">`)
+	if page >= 1 && page <= len(g.pages) {
+		for _, id := range g.pages[page-1] {
+			fmt.Fprintf(w, `<div class="gallery-item" data-software-id="%s"><h5>Project %s</h5></div>`, id, id)
+		}
+	}
+	fmt.Fprint(w, `</div>`)
+	if page == 1 {
+		fmt.Fprint(w, `<ul class="pagination"><li class="prev previous_page disabled"><a href="#">Previous</a></li>`)
+		for i := range g.listed {
+			fmt.Fprintf(w, `<li><a href="/project-gallery?page=%d">%d</a></li>`, i+1, i+1)
+		}
+		fmt.Fprint(w, `<li class="next next_page"><a rel="next" href="/project-gallery?page=2">Next</a></li></ul>`)
+	}
+}
+
+func TestFetchProjectsParallel(t *testing.T) {
+	g := &galleryServer{pages: [][]string{{"1", "2"}, {"3", "4"}, {"4", "5"}, {"6"}, {"7"}, {"8"}}, listed: 6}
+	d, _ := newTestClient(t, g)
+	d.galleryConcurrency = 3
+	gal, err := d.fetchProjectsNew(t.Context(), "e", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, p := range gal.projects {
+		got = append(got, p.ID)
+	}
+	// Project 4 moved from page 2 to page 3 while the gallery was fetched.
+	if want := []string{"1", "2", "3", "4", "5", "6", "7", "8"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if g.maxInFlight < 2 || g.maxInFlight > 3 {
+		t.Errorf("Unexpected concurrency %d", g.maxInFlight)
+	}
+
+	// The pages not listed in the pagination control are still fetched.
+	g.listed = 4
+	if gal, err = d.fetchProjectsNew(t.Context(), "e", nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(gal.projects) != 8 {
+		t.Errorf("Expected 8 projects, got %d", len(gal.projects))
+	}
+}
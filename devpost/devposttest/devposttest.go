@@ -0,0 +1,583 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+// Package devposttest is a fake devpost.com serving HTML fixtures, to test the
+// cache, the refresh loop and the web UI without network access.
+//
+// The fixtures directory is laid out as:
+//
+//	<event>/index.html    landing page, served at https://<event>.devpost.com/
+//	<event>/prizes.html   served at https://<event>.devpost.com/prizes
+//	<event>/gallery.html  project gallery; its div.gallery-item cards are
+//	                      paginated by the server
+//	software/<name>.html  project page, served at https://devpost.com/software/<name>
+//	people/<user>.html    profile page, served at https://devpost.com/<user>
+//	hackathons.json       hackathons listing, served at
+//	                      https://devpost.com/api/hackathons; its hackathons
+//	                      are filtered by the search and the status
+//
+// The gallery of an event without gallery.html is not published yet. A
+// project without a page gets a minimal one generated from its card. The
+// submissions search is not available.
+package devposttest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"maps"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/maruel/devpostdash/devpost"
+	"github.com/maruel/devpostdash/dom"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// FastRetry is the RetryPolicy used by Server.Client by default. It retries
+// quickly so injected failures don't slow down the tests.
+var FastRetry = devpost.RetryPolicy{
+	MaxAttempts:      4,
+	Backoff:          time.Millisecond,
+	MaxBackoff:       10 * time.Millisecond,
+	MaxRetryAfter:    time.Second,
+	Timeout:          10 * time.Second,
+	BreakerThreshold: 5,
+	BreakerCooldown:  100 * time.Millisecond,
+}
+
+// Options configures the Server. The zero value is valid.
+type Options struct {
+	// PageSize is the number of projects per gallery page. Defaults to 24 like
+	// devpost.
+	PageSize int
+	// Latency is added to every response.
+	Latency time.Duration
+}
+
+// Server is a fake devpost.com.
+//
+// It is safe to mutate the events while requests are being served.
+type Server struct {
+	fsys     fs.FS
+	srv      *httptest.Server
+	pageSize int
+
+	mu       sync.Mutex
+	latency  time.Duration
+	events   map[string]*event
+	failures []int
+	requests int
+	// submitted is the number of projects added by Simulate.
+	submitted int
+}
+
+// event is the mutable state of an event.
+type event struct {
+	// cards are the gallery cards, in gallery order.
+	cards []*html.Node
+}
+
+// NewServer starts a fake devpost.com serving the fixtures in fsys.
+//
+// The caller must call Close.
+func NewServer(fsys fs.FS, opts Options) (*Server, error) {
+	if opts.PageSize <= 0 {
+		opts.PageSize = 24
+	}
+	s := &Server{fsys: fsys, pageSize: opts.PageSize, latency: opts.Latency, events: map[string]*event{}}
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if !e.IsDir() || e.Name() == "software" || e.Name() == "people" {
+			continue
+		}
+		ev := &event{}
+		if ev.cards, err = loadCards(fsys, e.Name()+"/gallery.html"); err != nil {
+			return nil, err
+		}
+		s.events[e.Name()] = ev
+	}
+	s.srv = httptest.NewServer(s)
+	return s, nil
+}
+
+// Close stops the server.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Transport returns a http.RoundTripper sending the requests for devpost.com
+// and its subdomains to the server.
+func (s *Server) Transport() http.RoundTripper {
+	u, _ := url.Parse(s.srv.URL)
+	return &transport{target: u, rt: s.srv.Client().Transport}
+}
+
+// Client returns a devpost.Client fetching from the server. opts.Retry
+// defaults to FastRetry.
+func (s *Server) Client(ctx context.Context, opts devpost.ClientOptions) (devpost.Client, error) {
+	if opts.Retry.MaxAttempts <= 0 {
+		opts.Retry = FastRetry
+	}
+	return devpost.New(ctx, s.Transport(), opts)
+}
+
+// Requests returns the number of requests received so far.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// SetLatency changes the latency added to every response.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	s.latency = d
+	s.mu.Unlock()
+}
+
+// Fail makes the next n requests fail with the HTTP status code.
+func (s *Server) Fail(code, n int) {
+	s.mu.Lock()
+	for range n {
+		s.failures = append(s.failures, code)
+	}
+	s.mu.Unlock()
+}
+
+// AddLikes adds n likes to a project.
+func (s *Server) AddLikes(eventID, projectID string, n int) error {
+	return s.addCount(eventID, projectID, "like-count", n)
+}
+
+// AddComments adds n to the comments count of a project.
+func (s *Server) AddComments(eventID, projectID string, n int) error {
+	return s.addCount(eventID, projectID, "comment-count", n)
+}
+
+func (s *Server) addCount(eventID, projectID, class string, n int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e := s.events[eventID]
+	if e == nil {
+		return fmt.Errorf("unknown event %q", eventID)
+	}
+	i := slices.IndexFunc(e.cards, func(c *html.Node) bool { return dom.NodeAttr(c, "data-software-id") == projectID })
+	if i == -1 {
+		return fmt.Errorf("unknown project %q in event %q", projectID, eventID)
+	}
+	return addCount(e.cards[i], class, n)
+}
+
+// AddProject appends a new submission to the event's gallery. p.URL defaults
+// to the project page of p.ShortName.
+func (s *Server) AddProject(eventID string, p *devpost.Project) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addProject(eventID, p)
+}
+
+func (s *Server) addProject(eventID string, p *devpost.Project) error {
+	e := s.events[eventID]
+	if e == nil {
+		return fmt.Errorf("unknown event %q", eventID)
+	}
+	c := *p
+	if c.URL == "" {
+		c.URL = "https://devpost.com/software/" + c.ShortName
+	}
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, "card", &c); err != nil {
+		return err
+	}
+	nodes, err := html.ParseFragment(&buf, &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div})
+	if err != nil {
+		return err
+	}
+	for _, n := range nodes {
+		if n.Type == html.ElementNode {
+			e.cards = append(e.cards, n)
+			return nil
+		}
+	}
+	return errors.New("failed to render the card")
+}
+
+// Simulate mutates the events every period until ctx is canceled, like a
+// hackathon in progress: a random project gets a like and, once in a while, a
+// new project is submitted.
+func (s *Server) Simulate(ctx context.Context, every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for i := 1; ; i++ {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		s.mu.Lock()
+		ids := slices.Sorted(maps.Keys(s.events))
+		if len(ids) != 0 {
+			e := s.events[ids[rand.IntN(len(ids))]]
+			if len(e.cards) != 0 {
+				_ = addCount(e.cards[rand.IntN(len(e.cards))], "like-count", 1)
+			}
+			if i%10 == 0 {
+				s.submitted++
+				id := ids[rand.IntN(len(ids))]
+				_ = s.addProject(id, &devpost.Project{
+					ID:        strconv.Itoa(900000 + s.submitted),
+					ShortName: fmt.Sprintf("simulated-%d", s.submitted),
+					Title:     fmt.Sprintf("Simulated Project %d", s.submitted),
+					Tagline:   "Submitted while you were watching",
+				})
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// ServeHTTP implements http.Handler. The host of the request selects the
+// event.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests++
+	latency := s.latency
+	code := 0
+	if len(s.failures) != 0 {
+		code = s.failures[0]
+		s.failures = s.failures[1:]
+	}
+	s.mu.Unlock()
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if code != 0 {
+		http.Error(w, http.StatusText(code), code)
+		return
+	}
+	eventID, ok := strings.CutSuffix(r.Host, ".devpost.com")
+	if !ok {
+		if name, ok := strings.CutPrefix(r.URL.Path, "/software/"); ok {
+			s.serveProject(w, r, name)
+		} else if r.URL.Path == "/api/hackathons" {
+			s.serveHackathons(w, r)
+		} else if r.URL.Path == "/" {
+			_, _ = w.Write([]byte("<html><body><h1>Devpost</h1></body></html>"))
+		} else if user := strings.Trim(r.URL.Path, "/"); !strings.Contains(user, "/") {
+			s.serveFile(w, r, "people/"+user+".html")
+		} else {
+			http.NotFound(w, r)
+		}
+		return
+	}
+	switch r.URL.Path {
+	case "/":
+		s.serveFile(w, r, eventID+"/index.html")
+	case "/prizes":
+		s.serveFile(w, r, eventID+"/prizes.html")
+	case "/project-gallery":
+		page, err := strconv.Atoi(r.URL.Query().Get("page"))
+		if err != nil || page < 1 {
+			page = 1
+		}
+		s.serveGallery(w, r, eventID, page)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) serveFile(w http.ResponseWriter, r *http.Request, name string) {
+	b, err := fs.ReadFile(s.fsys, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(b)
+}
+
+func (s *Server) serveGallery(w http.ResponseWriter, r *http.Request, eventID string, page int) {
+	var buf bytes.Buffer
+	s.mu.Lock()
+	e := s.events[eventID]
+	if e == nil {
+		s.mu.Unlock()
+		http.NotFound(w, r)
+		return
+	}
+	data := struct {
+		Published bool
+		Cards     []template.HTML
+		Pages     []int
+		Page      int
+	}{Published: len(e.cards) != 0, Page: page}
+	if pages := (len(e.cards) + s.pageSize - 1) / s.pageSize; pages > 1 {
+		for i := range pages {
+			data.Pages = append(data.Pages, i+1)
+		}
+	}
+	for _, c := range e.cards[min((page-1)*s.pageSize, len(e.cards)):min(page*s.pageSize, len(e.cards))] {
+		var b strings.Builder
+		if err := html.Render(&b, c); err != nil {
+			s.mu.Unlock()
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// The card was parsed from the fixtures or rendered by AddProject.
+		data.Cards = append(data.Cards, template.HTML(b.String()))
+	}
+	s.mu.Unlock()
+	if err := templates.ExecuteTemplate(&buf, "gallery", &data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// serveHackathons serves the hackathons of hackathons.json matching the
+// query.
+func (s *Server) serveHackathons(w http.ResponseWriter, r *http.Request) {
+	b, err := fs.ReadFile(s.fsys, "hackathons.json")
+	if errors.Is(err, fs.ErrNotExist) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var data struct {
+		Hackathons []json.RawMessage `json:"hackathons"`
+	}
+	if err := json.Unmarshal(b, &data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	search := strings.ToLower(r.URL.Query().Get("search"))
+	status := r.URL.Query()["status[]"]
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	kept := []json.RawMessage{}
+	for _, raw := range data.Hackathons {
+		var h struct {
+			Title     string `json:"title"`
+			OpenState string `json:"open_state"`
+		}
+		if err := json.Unmarshal(raw, &h); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if page == 1 && strings.Contains(strings.ToLower(h.Title), search) && (len(status) == 0 || slices.Contains(status, h.OpenState)) {
+			kept = append(kept, raw)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"hackathons": kept,
+		"meta":       map[string]int{"total_count": len(kept), "per_page": 9},
+	})
+}
+
+func (s *Server) serveProject(w http.ResponseWriter, r *http.Request, name string) {
+	if _, err := fs.Stat(s.fsys, "software/"+name+".html"); err == nil {
+		s.serveFile(w, r, "software/"+name+".html")
+		return
+	}
+	// Generate a page from the card.
+	var data struct{ Title, Tagline string }
+	found := false
+	s.mu.Lock()
+	for _, e := range s.events {
+		for _, c := range e.cards {
+			if a := dom.FirstChild(c, dom.Tag("a"), dom.Class("block-wrapper-link")); a != nil && path.Base(dom.NodeAttr(a, "href")) == name {
+				if n := dom.FirstChild(c, dom.Tag("h5")); n != nil {
+					data.Title = dom.NodeText(n)
+				}
+				if n := dom.FirstChild(c, dom.Tag("p"), dom.Class("tagline")); n != nil {
+					data.Tagline = dom.NodeText(n)
+				}
+				found = true
+			}
+		}
+	}
+	s.mu.Unlock()
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, "project", &data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(buf.Bytes())
+}
+
+// transport sends the requests to the server, keeping the original host so
+// the server can route them.
+type transport struct {
+	target *url.URL
+	rt     http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if req.Host == "" {
+		req.Host = req.URL.Host
+	}
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return t.rt.RoundTrip(req)
+}
+
+// loadCards returns the gallery cards of the page, or nothing if the page
+// doesn't exist.
+func loadCards(fsys fs.FS, name string) ([]*html.Node, error) {
+	b, err := fs.ReadFile(fsys, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	doc, err := html.Parse(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	cards := slices.Collect(dom.YieldChildren(doc, dom.Tag("div"), dom.Class("gallery-item")))
+	for _, c := range cards {
+		c.Parent.RemoveChild(c)
+	}
+	return cards, nil
+}
+
+// addCount adds n to the count of the card's span with the class.
+func addCount(card *html.Node, class string, n int) error {
+	span := dom.FirstChild(card, dom.Tag("span"), dom.Class("count"), dom.Class(class))
+	if span == nil {
+		return fmt.Errorf("no %s in the card", class)
+	}
+	v, err := strconv.Atoi(dom.NodeText(span))
+	if err != nil {
+		return err
+	}
+	for span.FirstChild != nil {
+		span.RemoveChild(span.FirstChild)
+	}
+	span.AppendChild(&html.Node{Type: html.TextNode, Data: strconv.Itoa(v + n)})
+	return nil
+}
+
+var templates = template.Must(template.New("").Parse(`
+{{- define "card" -}}
+<div class="small-12 medium-6 large-4 columns gallery-item" data-software-id="{{.ID}}">
+  <a class="block-wrapper-link fade link-to-software" href="{{.URL}}">
+    <div class="software-entry">
+      <figure class="software-thumbnail">
+        <img class="software_thumbnail_image image-replacement" alt="{{.Title}}" src="{{.Image}}">
+      </figure>
+      <div class="software-entry-name entry-body">
+        <h5>{{.Title}}</h5>
+        <p class="small tagline">{{.Tagline}}</p>
+      </div>
+    </div>
+  </a>
+  {{- if .Winner}}
+  <aside class="entry-badge"><img class="winner" alt="Winner"></aside>
+  {{- end}}
+  <div class="software-entry-footer">
+    <div class="members">
+      {{- range .Team}}
+      <span class="user-profile-link" data-url="{{.URL}}"><img alt="{{.Name}}" title="{{.Name}}" src="{{.AvatarURL}}"></span>
+      {{- end}}
+    </div>
+    <div class="counts">
+      <span class="items"><i class="ss-heart"></i><span class="count like-count">{{.Likes}}</span></span>
+      <span class="items"><i class="ss-chat"></i><span class="count comment-count">{{.CommentCount}}</span></span>
+    </div>
+  </div>
+</div>
+{{- end}}
+
+{{- define "gallery" -}}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Project gallery | Devpost</title>
+</head>
+<body class="challenges-public">
+  <div id="container">
+    <section id="main" class="large-12 columns">
+    {{- if .Published}}
+    <div id="submission-gallery">
+      <div class="row">
+      {{- range .Cards}}
+      {{.}}
+      {{- end}}
+      </div>
+    </div>
+    {{- if .Pages}}
+    <ul class="pagination">
+      {{- range .Pages}}
+      <li{{if eq . $.Page}} class="current"{{end}}><a href="/project-gallery?page={{.}}">{{.}}</a></li>
+      {{- end}}
+    </ul>
+    {{- end}}
+    {{- else}}
+    <div class="row">
+      <h2>Coming soon</h2>
+      <p>The hackathon managers haven't published this gallery yet, but hang tight!</p>
+    </div>
+    {{- end}}
+    </section>
+  </div>
+</body>
+</html>
+{{- end}}
+
+{{- define "project" -}}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>{{.Title}} | Devpost</title>
+</head>
+<body>
+  <div id="container">
+    <div id="app-details-left"><p>{{.Tagline}}</p></div>
+  </div>
+</body>
+</html>
+{{- end}}
+`))
@@ -0,0 +1,180 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devposttest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/maruel/devpostdash/devpost"
+)
+
+func newServer(t *testing.T, opts Options) *Server {
+	s, err := NewServer(os.DirFS("testdata"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestServer(t *testing.T) {
+	s := newServer(t, Options{PageSize: 2})
+	d, err := s.Client(t.Context(), devpost.ClientOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	info, err := d.FetchEvent(t.Context(), "vibe")
+	if err != nil || info.Title != "Vibe Coding Hackathon" || info.Participants != 1234 {
+		t.Fatalf("FetchEvent() = %+v, %v", info, err)
+	}
+	prizes, err := d.FetchPrizes(t.Context(), "vibe")
+	if err != nil || len(prizes) != 2 {
+		t.Fatalf("FetchPrizes() = %+v, %v", prizes, err)
+	}
+	projects, err := d.FetchProjects(t.Context(), "vibe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, p := range projects {
+		ids = append(ids, p.ID)
+	}
+	if want := []string{"511001", "511002", "511003"}; !slices.Equal(ids, want) {
+		t.Fatalf("got %v, want %v", ids, want)
+	}
+	p, err := d.FetchProject(t.Context(), projects[0])
+	if err != nil || !slices.Equal(p.Tags, []string{"go", "sentiment-analysis"}) || p.Team[0].Role != "Everything" || len(p.Comments) != 2 || len(p.Updates) != 1 {
+		t.Fatalf("FetchProject() = %+v, %v", p, err)
+	}
+	// Generated from the card.
+	p, err = d.FetchProject(t.Context(), projects[1])
+	if err != nil || p.Description != "Beats to lint to" {
+		t.Fatalf("FetchProject() = %+v, %v", p, err)
+	}
+	person, err := d.FetchPerson(t.Context(), "alice")
+	if err != nil || person.Profile.Name != "Alice Liddell" || person.Profile.Location != "Oxford, UK" || person.Profile.Hackathons != 4 {
+		t.Fatalf("FetchPerson() = %+v, %v", person, err)
+	}
+	if _, err := d.FetchPerson(t.Context(), "nobody"); err == nil {
+		t.Error("expected an error")
+	}
+	hackathons, err := d.ListEvents(t.Context(), devpost.EventQuery{})
+	if err != nil || len(hackathons) != 2 || hackathons[0].ID != "vibe" || hackathons[1].Status != devpost.StatusUpcoming {
+		t.Fatalf("ListEvents() = %+v, %v", hackathons, err)
+	}
+	hackathons, err = d.ListEvents(t.Context(), devpost.EventQuery{Search: "gopher", Status: []string{devpost.StatusOpen}})
+	if err != nil || len(hackathons) != 0 {
+		t.Fatalf("ListEvents() = %+v, %v", hackathons, err)
+	}
+	if _, err := d.FetchEvent(t.Context(), "unknown"); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestServerUnpublished(t *testing.T) {
+	s := newServer(t, Options{})
+	d, err := s.Client(t.Context(), devpost.ClientOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	if projects, err := d.FetchProjects(t.Context(), "soon"); err != nil || len(projects) != 0 {
+		t.Fatalf("FetchProjects() = %d, %v", len(projects), err)
+	}
+	if err := s.AddProject("soon", &devpost.Project{ID: "1", ShortName: "first", Title: "First", Winner: true}); err != nil {
+		t.Fatal(err)
+	}
+	projects, err := d.FetchProjects(t.Context(), "soon")
+	if err != nil || len(projects) != 1 {
+		t.Fatalf("FetchProjects() = %d, %v", len(projects), err)
+	}
+	if p := projects[0]; p.Title != "First" || p.URL != "https://devpost.com/software/first" || !p.Winner {
+		t.Errorf("Unexpected project %+v", p)
+	}
+	if err := s.AddProject("unknown", &devpost.Project{ID: "1"}); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestServerFail(t *testing.T) {
+	s := newServer(t, Options{Latency: time.Millisecond})
+	d, err := s.Client(t.Context(), devpost.ClientOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	// Transient errors are retried.
+	start := s.Requests()
+	s.Fail(http.StatusServiceUnavailable, 2)
+	if _, err := d.FetchEvent(t.Context(), "vibe"); err != nil {
+		t.Fatal(err)
+	}
+	if n := s.Requests() - start; n != 3 {
+		t.Errorf("Expected 3 requests, got %d", n)
+	}
+	s.Fail(http.StatusForbidden, 1)
+	var herr *devpost.HTTPError
+	if _, err := d.FetchPrizes(t.Context(), "vibe"); !errors.As(err, &herr) || herr.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected a 403, got %v", err)
+	}
+}
+
+// TestCachedClient runs the refresh loop of the cached client against the
+// server while the event changes.
+func TestCachedClient(t *testing.T) {
+	s := newServer(t, Options{PageSize: 2})
+	raw, err := s.Client(t.Context(), devpost.ClientOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer raw.Close()
+	store, err := devpost.NewFileStore(filepath.Join(t.TempDir(), "store.json"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	d, err := devpost.NewCached(t.Context(), raw, time.Hour, 50*time.Millisecond, store, devpost.SchedulerOptions{QPS: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	if projects, err := d.FetchProjects(t.Context(), "vibe"); err != nil || len(projects) != 3 {
+		t.Fatalf("FetchProjects() = %d, %v", len(projects), err)
+	}
+
+	if err := s.AddLikes("vibe", "511002", 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddProject("vibe", &devpost.Project{ID: "511004", ShortName: "late-night-lambda", Title: "Late Night Lambda", Likes: 1}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Second)
+	defer cancel()
+	got := map[devpost.ChangeType]devpost.Change{}
+	for since := int64(0); len(got) < 2; {
+		changes, err := d.WaitChanges(ctx, "vibe", since)
+		if err != nil {
+			t.Fatalf("Got %v after %v", err, got)
+		}
+		for _, c := range changes {
+			got[c.Type] = c
+			since = c.Seq
+		}
+	}
+	if c := got[devpost.ChangeLikes]; c.ProjectID != "511002" || c.Likes != 12 || c.LikesDelta != 5 {
+		t.Errorf("Unexpected change %+v", c)
+	}
+	if c := got[devpost.ChangeAdded]; c.ProjectID != "511004" || c.Title != "Late Night Lambda" {
+		t.Errorf("Unexpected change %+v", c)
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devpost
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/maruel/devpostdash/dom"
+	"golang.org/x/net/html"
+	"golang.org/x/sync/errgroup"
+)
+
+// Discovery is a strategy to list the projects of an event.
+type Discovery string
+
+const (
+	// DiscoveryGallery walks the project gallery. It is empty until the
+	// hackathon managers publish it.
+	DiscoveryGallery Discovery = "gallery"
+	// DiscoverySubmissions walks the submissions search, which is sometimes
+	// available before the gallery.
+	DiscoverySubmissions Discovery = "submissions"
+)
+
+// DefaultDiscovery is the order in which the discovery strategies are tried
+// by default.
+var DefaultDiscovery = []Discovery{DiscoveryGallery, DiscoverySubmissions}
+
+// discoverers implements the discovery strategies. They return an empty list
+// when they found nothing, so the next strategy is tried.
+var discoverers = map[Discovery]func(d *client, ctx context.Context, eventID string, st *pageStats) (*gallery, error){
+	DiscoveryGallery:     (*client).fetchProjectsNew,
+	DiscoverySubmissions: (*client).fetchProjectsFromSubmissions,
+}
+
+// ParseDiscovery parses a comma separated list of strategies.
+func ParseDiscovery(s string) ([]Discovery, error) {
+	var out []Discovery
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if discoverers[Discovery(v)] == nil {
+			return nil, fmt.Errorf("unknown discovery strategy %q", v)
+		}
+		out = append(out, Discovery(v))
+	}
+	if len(out) == 0 {
+		return nil, errors.New("no discovery strategy")
+	}
+	return out, nil
+}
+
+// gallery is the list of projects of an event.
+type gallery struct {
+	projects []*Project
+	// hash is the hash of the pages the projects were parsed from. It is
+	// unchanged when the pages are unchanged.
+	hash string
+	// discovery is the strategy that found the projects.
+	discovery Discovery
+	// check is the validation of the parsed pages.
+	check *ScrapeCheck
+}
+
+// fetchGallery lists the projects with the first discovery strategy that
+// finds any.
+//
+// It implements galleryFetcher.
+func (d *client) fetchGallery(ctx context.Context, eventID string) (*gallery, error) {
+	var g *gallery
+	var err error
+	var st pageStats
+	start := time.Now()
+	defer func() {
+		n := 0
+		var discovery Discovery
+		if g != nil {
+			n = len(g.projects)
+			discovery = g.discovery
+		}
+		slog.InfoContext(ctx, "devpost", "projects", n, "event", eventID, "discovery", discovery, "dur", time.Since(start), "hits", st.hits, "misses", st.misses, "hit_ratio", d.pages.hitRatio(), "err", err)
+	}()
+	for _, s := range d.discovery {
+		var c *gallery
+		c, err = discoverers[s](d, ctx, eventID, &st)
+		var herr *HTTPError
+		if errors.As(err, &herr) && herr.StatusCode == http.StatusNotFound {
+			// The strategy isn't available for this event.
+			slog.InfoContext(ctx, "devpost", "msg", "discovery not available", "event", eventID, "discovery", s)
+			c, err = &gallery{check: newScrapeCheck(eventID, s, 0, pageCheck{}, false)}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		c.discovery = s
+		c.check.EventID = eventID
+		c.check.Discovery = s
+		if g == nil {
+			g = c
+		}
+		if len(c.projects) != 0 {
+			g = c
+			break
+		}
+	}
+	if !g.check.Healthy() {
+		slog.WarnContext(ctx, "devpost", "msg", "suspicious parse", "event", eventID, "discovery", g.discovery, "score", g.check.Score, "warnings", g.check.Warnings)
+	}
+	return g, nil
+}
+
+// galleryPage is one parsed page of a list of projects.
+type galleryPage struct {
+	projects []*Project
+	// last is true past the last page.
+	last bool
+	// pages is the number of pages listed in the pagination control, 0 if
+	// absent.
+	pages int
+	check pageCheck
+}
+
+func (d *client) fetchProjectsNew(ctx context.Context, eventID string, st *pageStats) (*gallery, error) {
+	return d.fetchPages(ctx, st, func(i int) string {
+		return fmt.Sprintf("https://%s.devpost.com/project-gallery?page=%d", eventID, i)
+	}, parseGalleryPage)
+}
+
+func (d *client) fetchProjectsFromSubmissions(ctx context.Context, eventID string, st *pageStats) (*gallery, error) {
+	return d.fetchPages(ctx, st, func(i int) string {
+		return fmt.Sprintf("https://%s.devpost.com/submissions/search?page=%d&sort=alpha&terms=&utf8=%%E2%%9C%%93", eventID, i)
+	}, parseSubmissionsPage)
+}
+
+// fetchPages fetches the pages of a paginated list of projects.
+//
+// The number of pages is read from the pagination control of the first page,
+// then the other pages are fetched concurrently.
+func (d *client) fetchPages(ctx context.Context, st *pageStats, pageURL func(i int) string, parse func(bod []byte) (galleryPage, error)) (*gallery, error) {
+	var mu sync.Mutex
+	fetch := func(ctx context.Context, i int) (galleryPage, [sha256.Size]byte, error) {
+		var ps pageStats
+		p, h, err := fetchParsed(ctx, d, pageURL(i), &ps, parse)
+		if st != nil {
+			mu.Lock()
+			st.hits += ps.hits
+			st.misses += ps.misses
+			mu.Unlock()
+		}
+		return p, h, err
+	}
+	var pages []galleryPage
+	var hashes [][sha256.Size]byte
+	first, h, err := fetch(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+	pages = append(pages, first)
+	hashes = append(hashes, h)
+	if !first.last && first.pages > 1 {
+		// Fetch the pages listed in the pagination concurrently. The throttle
+		// of the transport still applies.
+		rest := make([]galleryPage, first.pages-1)
+		restHashes := make([][sha256.Size]byte, first.pages-1)
+		eg, ctx2 := errgroup.WithContext(ctx)
+		eg.SetLimit(d.galleryConcurrency)
+		for i := range rest {
+			eg.Go(func() error {
+				var err error
+				rest[i], restHashes[i], err = fetch(ctx2, i+2)
+				return err
+			})
+		}
+		if err = eg.Wait(); err != nil {
+			return nil, err
+		}
+		pages = append(pages, rest...)
+		hashes = append(hashes, restHashes...)
+	}
+	// Walk past the known pages in case projects were added in the meantime
+	// or the pagination control was missing.
+	for i := len(pages) + 1; !pages[len(pages)-1].last; i++ {
+		var p galleryPage
+		if p, h, err = fetch(ctx, i); err != nil {
+			return nil, err
+		}
+		pages = append(pages, p)
+		hashes = append(hashes, h)
+	}
+
+	var projects []*Project
+	var check pageCheck
+	hash := sha256.New()
+	seen := map[string]bool{}
+	for i, p := range pages {
+		_, _ = hash.Write(hashes[i][:])
+		check.add(p.check)
+		for _, o := range p.projects {
+			// A project can move to the next page while the pages are being
+			// fetched.
+			if seen[o.ID] {
+				continue
+			}
+			seen[o.ID] = true
+			// The parsed projects are shared with the page cache.
+			c := *o
+			c.Team = slices.Clone(o.Team)
+			projects = append(projects, &c)
+		}
+	}
+	return &gallery{
+		projects: projects,
+		hash:     hex.EncodeToString(hash.Sum(nil)),
+		// Only the first page is checked for emptiness since a page past the
+		// end may link to projects elsewhere.
+		check: newScrapeCheck("", "", len(pages), check, pages[0].check.unexpectedEmpty()),
+	}, nil
+}
+
+func parseGalleryPage(bod []byte) (galleryPage, error) {
+	if bytes.Contains(bod, []byte("The hackathon managers haven't published this gallery yet, but hang tight!")) {
+		return galleryPage{last: true}, nil
+	}
+	doc, err := html.Parse(bytes.NewReader(bod))
+	if err != nil {
+		return galleryPage{}, err
+	}
+	p, check := parseProjects(doc)
+	return galleryPage{projects: p, last: len(p) == 0, pages: parsePageCount(doc), check: check}, nil
+}
+
+func parseSubmissionsPage(bod []byte) (galleryPage, error) {
+	// A bit of a hack but good enough.
+	if bytes.Contains(bod, []byte("There are no submissions which match your criteria.")) {
+		return galleryPage{last: true}, nil
+	}
+	return parseGalleryPage(bod)
+}
+
+// parsePageCount returns the highest page number linked from the pagination
+// control, or 0 if there's none.
+func parsePageCount(doc *html.Node) int {
+	n := dom.FirstChild(doc, dom.Tag("ul"), dom.Class("pagination"))
+	if n == nil {
+		return 0
+	}
+	pages := 0
+	for a := range dom.YieldChildren(n, dom.Tag("a")) {
+		u, err := url.Parse(dom.NodeAttr(a, "href"))
+		if err != nil {
+			continue
+		}
+		if i, err := strconv.Atoi(u.Query().Get("page")); err == nil {
+			pages = max(pages, i)
+		}
+	}
+	return pages
+}
@@ -0,0 +1,194 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devpost
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fixtureServer serves the files in testdata, keyed by path and page.
+type fixtureServer map[string]string
+
+func (f fixtureServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := f[r.URL.Path+"?page="+r.URL.Query().Get("page")]
+	if name == "" {
+		http.NotFound(w, r)
+		return
+	}
+	// Don't use http.ServeFile, it would answer the conditional requests.
+	b, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, _ = w.Write(b)
+}
+
+var (
+	galleryPublished = fixtureServer{
+		"/project-gallery?page=1": "gallery_page1.html",
+		"/project-gallery?page=2": "gallery_page2.html",
+		"/project-gallery?page=3": "gallery_empty.html",
+	}
+	galleryUnpublished = fixtureServer{
+		"/project-gallery?page=1": "gallery_unpublished.html",
+	}
+	submissions = fixtureServer{
+		"/submissions/search?page=1": "submissions_page1.html",
+		"/submissions/search?page=2": "submissions_empty.html",
+	}
+	submissionsEmpty = fixtureServer{
+		"/submissions/search?page=1": "submissions_empty.html",
+	}
+)
+
+func merge(servers ...fixtureServer) fixtureServer {
+	out := fixtureServer{}
+	for _, s := range servers {
+		for k, v := range s {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func TestParseGalleryPage(t *testing.T) {
+	b, err := os.ReadFile(filepath.Join("testdata", "gallery_page1.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := parseGalleryPage(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.last || p.pages != 2 || len(p.projects) != 2 {
+		t.Fatalf("Unexpected page %+v", p)
+	}
+	got := p.projects[0]
+	want := Project{
+		ID:           "511001",
+		ShortName:    "vibe-check",
+		Title:        "Vibe Check",
+		URL:          "https://devpost.com/software/vibe-check",
+		Tagline:      "Know the mood of your codebase",
+		Image:        "https://d112y698adiu2z.cloudfront.net/photos/production/software_thumbnail_photos/000/511001/datas/medium.png",
+		Team:         []Person{{Name: "alice", URL: "https://devpost.com/alice", AvatarURL: "https://d112y698adiu2z.cloudfront.net/photos/production/user_photos/alice.png"}},
+		Likes:        12,
+		CommentCount: 3,
+	}
+	if got.Hash() != want.Hash() {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+}
+
+func TestDiscovery(t *testing.T) {
+	data := []struct {
+		name      string
+		srv       fixtureServer
+		order     []Discovery
+		want      []string
+		discovery Discovery
+	}{
+		{
+			name:      "gallery",
+			srv:       merge(galleryPublished, submissions),
+			want:      []string{"511001", "511002", "511003"},
+			discovery: DiscoveryGallery,
+		},
+		{
+			name:      "unpublished",
+			srv:       merge(galleryUnpublished, submissions),
+			want:      []string{"511002", "511004"},
+			discovery: DiscoverySubmissions,
+		},
+		{
+			name:      "order",
+			srv:       merge(galleryPublished, submissions),
+			order:     []Discovery{DiscoverySubmissions, DiscoveryGallery},
+			want:      []string{"511002", "511004"},
+			discovery: DiscoverySubmissions,
+		},
+		{
+			name:      "no submissions",
+			srv:       merge(galleryPublished, submissionsEmpty),
+			order:     []Discovery{DiscoverySubmissions, DiscoveryGallery},
+			want:      []string{"511001", "511002", "511003"},
+			discovery: DiscoveryGallery,
+		},
+		{
+			name:      "not found",
+			srv:       galleryPublished,
+			order:     []Discovery{DiscoverySubmissions, DiscoveryGallery},
+			want:      []string{"511001", "511002", "511003"},
+			discovery: DiscoveryGallery,
+		},
+		{
+			name:      "nothing",
+			srv:       merge(galleryUnpublished, submissionsEmpty),
+			discovery: DiscoveryGallery,
+		},
+	}
+	for _, line := range data {
+		t.Run(line.name, func(t *testing.T) {
+			d, _ := newTestClient(t, line.srv)
+			d.discovery = DefaultDiscovery
+			if line.order != nil {
+				d.discovery = line.order
+			}
+			g, err := d.fetchGallery(t.Context(), "e")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, p := range g.projects {
+				got = append(got, p.ID)
+			}
+			if !slices.Equal(got, line.want) || g.discovery != line.discovery {
+				t.Errorf("got %v from %q, want %v from %q", got, g.discovery, line.want, line.discovery)
+			}
+		})
+	}
+}
+
+func TestParseDiscovery(t *testing.T) {
+	got, err := ParseDiscovery("submissions, gallery")
+	if err != nil || !slices.Equal(got, []Discovery{DiscoverySubmissions, DiscoveryGallery}) {
+		t.Errorf("ParseDiscovery() = %v, %v", got, err)
+	}
+	for _, s := range []string{"", "gallery,json"} {
+		if _, err := ParseDiscovery(s); err == nil {
+			t.Errorf("ParseDiscovery(%q) succeeded", s)
+		}
+	}
+}
+
+func TestCachedClientDiscovery(t *testing.T) {
+	d, _ := newTestClient(t, merge(galleryUnpublished, submissions))
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "store.json"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	cd, err := NewCached(t.Context(), d, time.Hour, 30*time.Minute, store, SchedulerOptions{Clock: &fakeClock{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cd.Close()
+	c := cd.(*cachedClient)
+	if _, err := c.fetchProjects(t.Context(), "e"); err != nil {
+		t.Fatal(err)
+	}
+	c.mu.Lock()
+	got := c.events["e"].Discovery
+	c.mu.Unlock()
+	if got != DiscoverySubmissions {
+		t.Errorf("Unexpected discovery %q", got)
+	}
+}
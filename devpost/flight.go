@@ -0,0 +1,62 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devpost
+
+import (
+	"context"
+	"sync"
+)
+
+// flightGroup coalesces concurrent calls for the same key into one.
+//
+// The call runs in its own goroutine so a waiter giving up doesn't abort it
+// for the others. The zero value is ready to use.
+type flightGroup[T any] struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall[T]
+	wg    sync.WaitGroup
+}
+
+type flightCall[T any] struct {
+	done chan struct{}
+	v    T
+	err  error
+}
+
+// do calls fn unless a call for key is already in flight, and waits for the
+// result or for ctx to be done. fn must not depend on ctx.
+func (g *flightGroup[T]) do(ctx context.Context, key string, fn func() (T, error)) (T, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = map[string]*flightCall[T]{}
+	}
+	call := g.calls[key]
+	if call == nil {
+		call = &flightCall[T]{done: make(chan struct{})}
+		g.calls[key] = call
+		g.wg.Add(1)
+		go func() {
+			defer g.wg.Done()
+			call.v, call.err = fn()
+			g.mu.Lock()
+			delete(g.calls, key)
+			g.mu.Unlock()
+			close(call.done)
+		}()
+	}
+	g.mu.Unlock()
+	select {
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	case <-call.done:
+		return call.v, call.err
+	}
+}
+
+// wait waits for the calls in flight to complete.
+func (g *flightGroup[T]) wait() {
+	g.wg.Wait()
+}
@@ -0,0 +1,74 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devpost
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFlightGroup(t *testing.T) {
+	var g flightGroup[int]
+	var calls atomic.Int32
+	gate := make(chan struct{})
+	fn := func() (int, error) {
+		calls.Add(1)
+		<-gate
+		return 42, nil
+	}
+
+	// A waiter that gives up doesn't abort the call for the others.
+	ctx, cancel := context.WithCancel(t.Context())
+	cancelled := make(chan error)
+	go func() {
+		_, err := g.do(ctx, "k", fn)
+		cancelled <- err
+	}()
+	var wg sync.WaitGroup
+	results := make(chan int, 10)
+	started := make(chan struct{})
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			started <- struct{}{}
+			v, err := g.do(t.Context(), "k", fn)
+			if err != nil {
+				t.Error(err)
+			}
+			results <- v
+		}()
+	}
+	for range 10 {
+		<-started
+	}
+	// Give the goroutines time to join the call.
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	if err := <-cancelled; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	close(gate)
+	wg.Wait()
+	close(results)
+	for v := range results {
+		if v != 42 {
+			t.Errorf("Got %d", v)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("Expected 1 call, got %d", n)
+	}
+
+	// Once done, the next call runs again.
+	if v, err := g.do(t.Context(), "k", func() (int, error) { return 1, nil }); v != 1 || err != nil {
+		t.Errorf("do() = %d, %v", v, err)
+	}
+	g.wait()
+}
@@ -0,0 +1,182 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devpost
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maruel/devpostdash/dom"
+	"golang.org/x/net/html"
+)
+
+// Hackathon statuses, as filtered on devpost.com/hackathons.
+const (
+	StatusOpen     = "open"
+	StatusUpcoming = "upcoming"
+	StatusEnded    = "ended"
+)
+
+// EventQuery selects the hackathons listed by ListEvents. The zero value
+// lists the first page of all the hackathons.
+type EventQuery struct {
+	// Search is matched by devpost against the title and the description.
+	Search string `json:"search,omitempty"`
+	// Status is any of StatusOpen, StatusUpcoming and StatusEnded.
+	Status []string `json:"status,omitempty"`
+	// Themes is the list of devpost themes, e.g. "Machine Learning/AI".
+	Themes []string `json:"themes,omitempty"`
+	// Page is the 1-based page of results. Defaults to 1.
+	Page int `json:"page,omitempty"`
+}
+
+// Validate returns an error if the query has an unknown status or an invalid
+// page.
+func (q *EventQuery) Validate() error {
+	for _, s := range q.Status {
+		if s != StatusOpen && s != StatusUpcoming && s != StatusEnded {
+			return &HTTPError{StatusCode: http.StatusBadRequest, Body: fmt.Appendf(nil, "invalid status %q", s)}
+		}
+	}
+	if q.Page < 0 {
+		return &HTTPError{StatusCode: http.StatusBadRequest, Body: []byte("invalid page")}
+	}
+	return nil
+}
+
+// url returns the URL of the listing. It is also used as the cache key so the
+// filters are sorted.
+func (q *EventQuery) url() string {
+	v := url.Values{}
+	if s := strings.TrimSpace(q.Search); s != "" {
+		v.Set("search", s)
+	}
+	for _, s := range slices.Sorted(slices.Values(q.Status)) {
+		v.Add("status[]", s)
+	}
+	for _, t := range slices.Sorted(slices.Values(q.Themes)) {
+		v.Add("themes[]", t)
+	}
+	if q.Page > 1 {
+		v.Set("page", strconv.Itoa(q.Page))
+	}
+	u := "https://devpost.com/api/hackathons"
+	if len(v) != 0 {
+		u += "?" + v.Encode()
+	}
+	return u
+}
+
+// Hackathon is a hackathon as listed on devpost.com/hackathons. Only the
+// fields of EventInfo returned by the listing API are set.
+type Hackathon struct {
+	EventInfo
+	// Status is one of StatusOpen, StatusUpcoming and StatusEnded.
+	Status string `json:"status"`
+	// TimeLeft is the status as displayed, e.g. "12 days left".
+	TimeLeft string   `json:"time_left"`
+	Host     string   `json:"host"`
+	Themes   []string `json:"themes"`
+}
+
+// apiHackathons is a page of https://devpost.com/api/hackathons. The tiles of
+// devpost.com/hackathons are rendered client side from it.
+type apiHackathons struct {
+	Hackathons []struct {
+		Title             string `json:"title"`
+		URL               string `json:"url"`
+		ThumbnailURL      string `json:"thumbnail_url"`
+		DisplayedLocation struct {
+			Location string `json:"location"`
+		} `json:"displayed_location"`
+		OpenState string `json:"open_state"`
+		TimeLeft  string `json:"time_left_to_submission"`
+		Period    string `json:"submission_period_dates"`
+		Themes    []struct {
+			Name string `json:"name"`
+		} `json:"themes"`
+		// PrizeAmount is HTML, e.g. "$<span data-currency-value>50,000</span>".
+		PrizeAmount   string `json:"prize_amount"`
+		Registrations int    `json:"registrations_count"`
+		Organization  string `json:"organization_name"`
+	} `json:"hackathons"`
+}
+
+// parseHackathons parses a page of the hackathons API.
+//
+// Hackathons not hosted on a devpost.com subdomain are skipped since they
+// can't be tracked.
+func parseHackathons(b []byte) ([]Hackathon, error) {
+	var page apiHackathons
+	if err := json.Unmarshal(b, &page); err != nil {
+		return nil, fmt.Errorf("failed to parse the hackathons: %w", err)
+	}
+	out := []Hackathon{}
+	for _, v := range page.Hackathons {
+		id := eventIDFromURL(normalizeURL(v.URL))
+		if id == "" {
+			continue
+		}
+		h := Hackathon{
+			EventInfo: EventInfo{
+				ID:           id,
+				Title:        strings.TrimSpace(v.Title),
+				URL:          "https://" + id + ".devpost.com/",
+				BannerURL:    normalizeURL(v.ThumbnailURL),
+				Location:     strings.TrimSpace(v.DisplayedLocation.Location),
+				Participants: v.Registrations,
+			},
+			Status:   parseStatus(v.OpenState, v.TimeLeft),
+			TimeLeft: strings.TrimSpace(v.TimeLeft),
+			Host:     strings.TrimSpace(v.Organization),
+		}
+		h.StartsAt, h.EndsAt = parseSubmissionPeriod(v.Period)
+		h.Deadline = h.EndsAt
+		if doc, err := html.Parse(strings.NewReader(v.PrizeAmount)); err == nil {
+			h.PrizeTotal = strings.ReplaceAll(dom.NodeText(doc), " ", "")
+			h.PrizeAmount = parseAmount(h.PrizeTotal)
+		}
+		for _, t := range v.Themes {
+			if t.Name != "" {
+				h.Themes = append(h.Themes, t.Name)
+			}
+		}
+		out = append(out, h)
+	}
+	return out, nil
+}
+
+// parseStatus returns the status from the open state, or from the time left
+// as displayed.
+func parseStatus(state, text string) string {
+	switch state {
+	case StatusOpen, StatusUpcoming, StatusEnded:
+		return state
+	}
+	switch t := strings.ToLower(text); {
+	case strings.Contains(t, "left"):
+		return StatusOpen
+	case strings.Contains(t, "start"), strings.Contains(t, "upcoming"):
+		return StatusUpcoming
+	case strings.Contains(t, "ended"):
+		return StatusEnded
+	}
+	return ""
+}
+
+// listingEntry is a cached page of devpost.com/hackathons.
+type listingEntry struct {
+	hackathons []Hackathon
+	fetched    time.Time
+}
+
+// maxListings is the number of listing pages kept in memory.
+const maxListings = 100
@@ -0,0 +1,152 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devpost
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestParseHackathons parses testdata/hackathons.json. devpost.com/hackathons
+// is rendered client side so its HTML has no hackathon to parse; the fixture
+// follows the shape of https://devpost.com/api/hackathons and should be
+// replaced with a saved response, e.g.:
+//
+//	curl -o testdata/hackathons.json 'https://devpost.com/api/hackathons?search=vibe'
+func TestParseHackathons(t *testing.T) {
+	b, err := os.ReadFile(filepath.Join("testdata", "hackathons.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := parseHackathons(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, h := range got {
+		ids = append(ids, h.ID+":"+h.Status)
+	}
+	if want := []string{"vibe-coding-hackathon:open", "gopher-jam:upcoming", "retro-hack:ended"}; !slices.Equal(ids, want) {
+		t.Fatalf("got %v, want %v", ids, want)
+	}
+	h := got[0]
+	if h.Title != "Vibe Coding Hackathon" || h.URL != "https://vibe-coding-hackathon.devpost.com/" {
+		t.Errorf("Unexpected hackathon %+v", h)
+	}
+	if h.BannerURL != "https://d112y698adiu2z.cloudfront.net/photos/production/challenge_thumbnails/000/001/vibe.png" || h.Location != "Online" || h.Host != "Vibe Corp" {
+		t.Errorf("Unexpected hackathon %+v", h)
+	}
+	if h.PrizeTotal != "$50,000" || h.PrizeAmount != 50000 || h.Participants != 1234 || h.TimeLeft != "12 days left" {
+		t.Errorf("Unexpected hackathon %+v", h)
+	}
+	if !h.StartsAt.Equal(time.Date(2025, 6, 20, 0, 0, 0, 0, time.UTC)) || !h.Deadline.Equal(time.Date(2025, 7, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected dates %s - %s", h.StartsAt, h.Deadline)
+	}
+	if !slices.Equal(h.Themes, []string{"Beginner Friendly", "Machine Learning/AI"}) {
+		t.Errorf("Themes = %q", h.Themes)
+	}
+	if h := got[1]; h.URL != "https://gopher-jam.devpost.com/" || h.PrizeTotal != "€2,500" || h.Location != "San Francisco, CA" {
+		t.Errorf("Unexpected hackathon %+v", h)
+	}
+	if _, err := parseHackathons([]byte("<html>")); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestEventQuery(t *testing.T) {
+	for _, tc := range []struct {
+		q    EventQuery
+		want string
+	}{
+		{EventQuery{}, "https://devpost.com/api/hackathons"},
+		{EventQuery{Search: " ai ", Page: 1}, "https://devpost.com/api/hackathons?search=ai"},
+		{
+			EventQuery{Status: []string{StatusUpcoming, StatusOpen}, Themes: []string{"Web", "Machine Learning/AI"}, Page: 2},
+			"https://devpost.com/api/hackathons?page=2&status%5B%5D=open&status%5B%5D=upcoming&themes%5B%5D=Machine+Learning%2FAI&themes%5B%5D=Web",
+		},
+	} {
+		if err := tc.q.Validate(); err != nil {
+			t.Errorf("%+v: %v", tc.q, err)
+		}
+		if got := tc.q.url(); got != tc.want {
+			t.Errorf("%+v: got %q, want %q", tc.q, got, tc.want)
+		}
+	}
+	for _, q := range []EventQuery{{Status: []string{"closed"}}, {Page: -1}} {
+		var herr *HTTPError
+		if err := q.Validate(); !errors.As(err, &herr) || herr.StatusCode != http.StatusBadRequest {
+			t.Errorf("%+v: expected a 400, got %v", q, err)
+		}
+	}
+}
+
+func TestClientListEvents(t *testing.T) {
+	d, _ := newTestClient(t, fixtureServer{"/api/hackathons?page=": "hackathons.json"})
+	got, err := d.ListEvents(t.Context(), EventQuery{Search: "vibe"})
+	if err != nil || len(got) != 3 || got[0].LastRefresh.IsZero() {
+		t.Fatalf("ListEvents() = %+v, %v", got, err)
+	}
+	// There's a page for every search so they are not kept in the page cache.
+	if n := d.pages.lru.Len(); n != 0 {
+		t.Errorf("Expected no cached page, got %d", n)
+	}
+	if _, err := d.ListEvents(t.Context(), EventQuery{Status: []string{"closed"}}); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+// listingClient counts the listings fetched.
+type listingClient struct {
+	fakeClient
+	fetches atomic.Int32
+}
+
+func (l *listingClient) ListEvents(ctx context.Context, q EventQuery) ([]Hackathon, error) {
+	l.fetches.Add(1)
+	return []Hackathon{{EventInfo: EventInfo{ID: "e", Title: q.Search}}}, nil
+}
+
+func TestCachedClientListEvents(t *testing.T) {
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "store.json"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	l := &listingClient{}
+	d, err := NewCached(t.Context(), l, time.Hour, 30*time.Minute, store, SchedulerOptions{Clock: &fakeClock{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	for range 2 {
+		got, err := d.ListEvents(t.Context(), EventQuery{Search: "go", Status: []string{StatusOpen, StatusEnded}})
+		if err != nil || len(got) != 1 || got[0].Title != "go" {
+			t.Fatalf("ListEvents() = %+v, %v", got, err)
+		}
+	}
+	// The order of the filters doesn't matter.
+	if _, err := d.ListEvents(t.Context(), EventQuery{Search: "go", Status: []string{StatusEnded, StatusOpen}}); err != nil {
+		t.Fatal(err)
+	}
+	if n := l.fetches.Load(); n != 1 {
+		t.Errorf("Expected 1 fetch, got %d", n)
+	}
+	if _, err := d.ListEvents(t.Context(), EventQuery{Search: "rust"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := l.fetches.Load(); n != 2 {
+		t.Errorf("Expected 2 fetches, got %d", n)
+	}
+	if _, err := d.ListEvents(t.Context(), EventQuery{Page: -1}); err == nil {
+		t.Error("expected an error")
+	}
+}
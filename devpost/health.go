@@ -0,0 +1,154 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devpost
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/maruel/devpostdash/dom"
+	"golang.org/x/net/html"
+)
+
+const (
+	// healthyScore is the score under which a parse raises warnings.
+	healthyScore = 0.9
+	// minScore is the score under which a parse is considered broken and
+	// doesn't replace the cached projects.
+	minScore = 0.5
+)
+
+// ErrBrokenParse is returned when the parsed projects look broken, likely
+// because devpost changed its markup. The cached projects are kept.
+var ErrBrokenParse = errors.New("devpost markup looks broken")
+
+// ScrapeCheck is the validation of the project list parsed for an event.
+type ScrapeCheck struct {
+	EventID   string    `json:"event_id"`
+	Discovery Discovery `json:"discovery,omitempty"`
+	Checked   time.Time `json:"checked"`
+	Pages     int       `json:"pages"`
+	Projects  int       `json:"projects"`
+	// MissingID, MissingTitle and MissingURL are the number of projects
+	// without the field.
+	MissingID    int `json:"missing_id,omitempty"`
+	MissingTitle int `json:"missing_title,omitempty"`
+	MissingURL   int `json:"missing_url,omitempty"`
+	// BadCounts is the number of projects whose likes or comments count
+	// couldn't be parsed.
+	BadCounts int `json:"bad_counts,omitempty"`
+	// UnexpectedEmpty is set when a page has no project while it links to
+	// projects.
+	UnexpectedEmpty bool `json:"unexpected_empty,omitempty"`
+	// Score is 1 when everything parsed correctly, 0 when nothing did.
+	Score    float64  `json:"score"`
+	Warnings []string `json:"warnings,omitempty"`
+	// Rejected is set when the projects were not stored because the cached
+	// ones looked better.
+	Rejected bool `json:"rejected,omitempty"`
+}
+
+// Healthy returns true if the parse didn't raise any warning.
+func (s *ScrapeCheck) Healthy() bool {
+	return s.Score >= healthyScore
+}
+
+// ScraperHealth is the latest ScrapeCheck of each event.
+type ScraperHealth struct {
+	// Healthy is false if any event's latest check raised warnings.
+	Healthy bool          `json:"healthy"`
+	Events  []ScrapeCheck `json:"events"`
+}
+
+// pageCheck is the validation of one parsed page of projects.
+type pageCheck struct {
+	projects     int
+	missingID    int
+	missingTitle int
+	missingURL   int
+	badCounts    int
+	// softwareLinks is the number of links to project pages.
+	softwareLinks int
+}
+
+func (c *pageCheck) add(o pageCheck) {
+	c.projects += o.projects
+	c.missingID += o.missingID
+	c.missingTitle += o.missingTitle
+	c.missingURL += o.missingURL
+	c.badCounts += o.badCounts
+	c.softwareLinks += o.softwareLinks
+}
+
+// unexpectedEmpty returns true if the page links to projects but none were
+// parsed.
+func (c *pageCheck) unexpectedEmpty() bool {
+	return c.projects == 0 && c.softwareLinks != 0
+}
+
+// checkProject records the fields missing from p.
+func (c *pageCheck) checkProject(p *Project) {
+	c.projects++
+	if p.ID == "" {
+		c.missingID++
+	}
+	if p.Title == "" {
+		c.missingTitle++
+	}
+	if p.URL == "" {
+		c.missingURL++
+	}
+}
+
+// countSoftwareLinks returns the number of links to project pages in the
+// document.
+func countSoftwareLinks(doc *html.Node) int {
+	n := 0
+	for a := range dom.YieldChildren(doc, dom.Tag("a")) {
+		if strings.Contains(dom.NodeAttr(a, "href"), "devpost.com/software/") {
+			n++
+		}
+	}
+	return n
+}
+
+// newScrapeCheck scores the validation of the pages.
+func newScrapeCheck(eventID string, discovery Discovery, pages int, c pageCheck, unexpectedEmpty bool) *ScrapeCheck {
+	s := &ScrapeCheck{
+		EventID:         eventID,
+		Discovery:       discovery,
+		Checked:         time.Now(),
+		Pages:           pages,
+		Projects:        c.projects,
+		MissingID:       c.missingID,
+		MissingTitle:    c.missingTitle,
+		MissingURL:      c.missingURL,
+		BadCounts:       c.badCounts,
+		UnexpectedEmpty: unexpectedEmpty,
+		Score:           1,
+	}
+	if unexpectedEmpty {
+		s.Score = 0
+		s.Warnings = append(s.Warnings, "a page links to projects but none were parsed")
+	}
+	for _, f := range []struct {
+		n    int
+		what string
+	}{
+		{c.missingID, "without an ID"},
+		{c.missingTitle, "without a title"},
+		{c.missingURL, "without a URL"},
+		{c.badCounts, "with unreadable likes or comments"},
+	} {
+		if f.n == 0 {
+			continue
+		}
+		s.Score = min(s.Score, 1-float64(f.n)/float64(c.projects))
+		s.Warnings = append(s.Warnings, fmt.Sprintf("%d/%d projects %s", f.n, c.projects, f.what))
+	}
+	return s
+}
@@ -0,0 +1,103 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devpost
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScrapeCheck(t *testing.T) {
+	data := []struct {
+		name    string
+		page1   string
+		score   float64
+		healthy bool
+	}{
+		{"ok", "gallery_page1.html", 1, true},
+		{"renamed", "gallery_renamed.html", 0, false},
+		// One project of the 3 has an unreadable like count.
+		{"bad counts", "gallery_bad_counts.html", 1 - 1./3, false},
+		{"unpublished", "gallery_unpublished.html", 1, true},
+	}
+	for _, line := range data {
+		t.Run(line.name, func(t *testing.T) {
+			srv := merge(galleryPublished, submissionsEmpty)
+			srv["/project-gallery?page=1"] = line.page1
+			d, _ := newTestClient(t, srv)
+			g, err := d.fetchGallery(t.Context(), "e")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(g.check.Score-line.score) > 1e-9 || g.check.Healthy() != line.healthy || g.check.EventID != "e" {
+				t.Errorf("Unexpected check %+v", g.check)
+			}
+			if !line.healthy && len(g.check.Warnings) == 0 {
+				t.Error("Expected warnings")
+			}
+		})
+	}
+}
+
+func TestCachedClientBrokenParse(t *testing.T) {
+	var srv atomic.Pointer[fixtureServer]
+	ok := merge(galleryPublished, submissionsEmpty)
+	srv.Store(&ok)
+	d, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		srv.Load().ServeHTTP(w, r)
+	}))
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "store.json"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	cd, err := NewCached(t.Context(), d, time.Hour, 30*time.Minute, store, SchedulerOptions{Clock: &fakeClock{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cd.Close()
+	c := cd.(*cachedClient)
+	if _, err := c.fetchProjects(t.Context(), "e"); err != nil {
+		t.Fatal(err)
+	}
+	h, err := c.ScraperHealth(t.Context())
+	if err != nil || !h.Healthy || len(h.Events) != 1 {
+		t.Fatalf("ScraperHealth() = %+v, %v", h, err)
+	}
+
+	// devpost renamed a class.
+	broken := merge(ok, fixtureServer{"/project-gallery?page=1": "gallery_renamed.html"})
+	srv.Store(&broken)
+	if _, err := c.fetchProjects(t.Context(), "e"); !errors.Is(err, ErrBrokenParse) {
+		t.Fatalf("Expected ErrBrokenParse, got %v", err)
+	}
+	c.mu.Lock()
+	n := len(c.events["e"].Projects)
+	c.mu.Unlock()
+	if n != 3 {
+		t.Errorf("The cached projects were overwritten, got %d", n)
+	}
+	h, err = c.ScraperHealth(t.Context())
+	if err != nil || h.Healthy || len(h.Events) != 1 || !h.Events[0].Rejected || !h.Events[0].UnexpectedEmpty {
+		t.Errorf("ScraperHealth() = %+v, %v", h, err)
+	}
+
+	// The checks are persisted so the breakage is still reported after a
+	// restart.
+	cd2, err := NewCached(t.Context(), d, time.Hour, 30*time.Minute, store, SchedulerOptions{Clock: &fakeClock{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cd2.Close()
+	h, err = cd2.ScraperHealth(t.Context())
+	if err != nil || h.Healthy || len(h.Events) != 1 || !h.Events[0].Rejected {
+		t.Errorf("ScraperHealth() = %+v, %v", h, err)
+	}
+}
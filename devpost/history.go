@@ -0,0 +1,91 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devpost
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	// maxSamples bounds the history kept per project.
+	maxSamples = 500
+	// trendWindow is the period over which LikesVelocity is calculated.
+	trendWindow = time.Hour
+)
+
+// Sample is a point in time measurement of a project's popularity.
+type Sample struct {
+	Time     time.Time `json:"time"`
+	Likes    int       `json:"likes"`
+	Comments int       `json:"comments"`
+}
+
+// recordHistory appends a sample for each project and updates their
+// LikesVelocity. History for projects that disappeared is dropped.
+func recordHistory(history map[string][]Sample, projects []*Project, now time.Time) map[string][]Sample {
+	out := make(map[string][]Sample, len(projects))
+	for _, p := range projects {
+		h := appendSample(history[p.ID], Sample{Time: now, Likes: p.Likes, Comments: p.CommentCount})
+		p.LikesVelocity = likesVelocity(h, now, trendWindow)
+		out[p.ID] = h
+	}
+	return out
+}
+
+// updateVelocity returns projects with their LikesVelocity updated as of now,
+// for when the history didn't change. The projects whose velocity changed are
+// copied, the others are shared.
+func updateVelocity(history map[string][]Sample, projects []*Project, now time.Time) []*Project {
+	out := projects
+	copied := false
+	for i, p := range projects {
+		v := likesVelocity(history[p.ID], now, trendWindow)
+		if v == p.LikesVelocity {
+			continue
+		}
+		if !copied {
+			out = slices.Clone(projects)
+			copied = true
+		}
+		c := *p
+		c.LikesVelocity = v
+		out[i] = &c
+	}
+	return out
+}
+
+// appendSample adds s to h if the values changed, keeping at most maxSamples.
+func appendSample(h []Sample, s Sample) []Sample {
+	if n := len(h); n != 0 && h[n-1].Likes == s.Likes && h[n-1].Comments == s.Comments {
+		return h
+	}
+	h = append(h, s)
+	if len(h) > maxSamples {
+		h = append([]Sample(nil), h[len(h)-maxSamples:]...)
+	}
+	return h
+}
+
+// likesVelocity returns the number of likes per hour gained over window.
+//
+// Since samples are only recorded on change, the baseline is the last sample
+// before the window started.
+func likesVelocity(h []Sample, now time.Time, window time.Duration) float64 {
+	if len(h) == 0 {
+		return 0
+	}
+	since := now.Add(-window)
+	base := h[0]
+	for _, s := range h[1:] {
+		if s.Time.After(since) {
+			break
+		}
+		base = s
+	}
+	// Do not inflate the velocity of projects that were just discovered.
+	d := max(now.Sub(base.Time), window)
+	return float64(h[len(h)-1].Likes-base.Likes) / d.Hours()
+}
@@ -0,0 +1,46 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devpost
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecordHistory(t *testing.T) {
+	now := time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+	p := &Project{ID: "1", Likes: 10}
+	h := recordHistory(nil, []*Project{p}, now.Add(-2*time.Hour))
+	// Unchanged values are not recorded.
+	h = recordHistory(h, []*Project{p}, now.Add(-90*time.Minute))
+	if len(h["1"]) != 1 {
+		t.Fatalf("Expected 1 sample, got %d", len(h["1"]))
+	}
+	p.Likes = 20
+	h = recordHistory(h, []*Project{p}, now.Add(-30*time.Minute))
+	p.Likes = 30
+	h = recordHistory(h, []*Project{p}, now)
+	if len(h["1"]) != 3 {
+		t.Fatalf("Expected 3 samples, got %d", len(h["1"]))
+	}
+	// The baseline is 10 likes two hours ago.
+	if p.LikesVelocity != 10 {
+		t.Errorf("LikesVelocity = %f", p.LikesVelocity)
+	}
+	// Projects that disappeared are dropped.
+	if h = recordHistory(h, nil, now); len(h) != 0 {
+		t.Errorf("Expected empty history, got %v", h)
+	}
+}
+
+func TestAppendSampleBounded(t *testing.T) {
+	var h []Sample
+	for i := range maxSamples + 10 {
+		h = appendSample(h, Sample{Likes: i})
+	}
+	if len(h) != maxSamples || h[0].Likes != 10 {
+		t.Errorf("Unexpected history: len=%d first=%d", len(h), h[0].Likes)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devpost
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// storeVersion is the version of the on-disk format written by this binary.
+//
+// Bump it and register a migration whenever a stored type changes in a way
+// that older data would be decoded incorrectly.
+const storeVersion = 2
+
+// ErrNewerVersion is returned when opening a store written by a newer binary.
+var ErrNewerVersion = errors.New("store was written by a newer version")
+
+// migrations upgrades the on-disk data from version N to N+1.
+var migrations = map[int]func(data *serializedCache) error{
+	1: migrateV1,
+}
+
+// migrate upgrades data to storeVersion in place. It returns true if data was
+// modified.
+func migrate(data *serializedCache) (bool, error) {
+	if data.Version == 0 {
+		// Files written before the version was checked.
+		data.Version = 1
+	}
+	if data.Version > storeVersion {
+		return false, fmt.Errorf("%w: %d > %d", ErrNewerVersion, data.Version, storeVersion)
+	}
+	changed := false
+	for data.Version < storeVersion {
+		m := migrations[data.Version]
+		if m == nil {
+			return changed, fmt.Errorf("no migration from version %d", data.Version)
+		}
+		if err := m(data); err != nil {
+			return changed, fmt.Errorf("failed to migrate from version %d: %w", data.Version, err)
+		}
+		slog.Info("devpost", "msg", "migrated store", "from", data.Version, "to", data.Version+1)
+		data.Version++
+		changed = true
+	}
+	return changed, nil
+}
+
+// migrateV1 clears the projects' last_refresh.
+//
+// Version 2 scrapes the gallery, links, challenges, prizes and team roles
+// from the project page. Projects fetched by version 1 lack them and would not
+// be refetched until they become stale.
+func migrateV1(data *serializedCache) error {
+	for id, raw := range data.Events {
+		var e map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &e); err != nil {
+			return fmt.Errorf("event %s: %w", id, err)
+		}
+		var projects []map[string]json.RawMessage
+		if b := e["projects"]; b != nil {
+			if err := json.Unmarshal(b, &projects); err != nil {
+				return fmt.Errorf("event %s: %w", id, err)
+			}
+		}
+		for _, p := range projects {
+			delete(p, "last_refresh")
+		}
+		b, err := json.Marshal(projects)
+		if err != nil {
+			return err
+		}
+		e["projects"] = b
+		if data.Events[id], err = json.Marshal(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backupFile copies path to a file suffixed with the version it was written
+// with, before it is rewritten by a migration.
+func backupFile(path string, version int) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst := fmt.Sprintf("%s.v%d.bak", path, version)
+	if err := writeFileAtomic(dst, func(w io.Writer) error {
+		_, err := io.Copy(w, src)
+		return err
+	}); err != nil {
+		return err
+	}
+	slog.Info("devpost", "msg", "backed up store", "path", dst)
+	return nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devpost
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const storeV1 = `{
+  "version": 1,
+  "events": {
+    "e": {"id": "e", "projects": [{"id": "1", "title": "One", "likes": 3, "last_refresh": "2025-07-01T00:00:00Z"}]}
+  },
+  "roasts": {
+    "1": {"content": "lol"}
+  }
+}`
+
+func TestMigrateFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	if err := os.WriteFile(path, []byte(storeV1), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewFileStore(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	p, err := s.GetProject("e", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Title != "One" || p.Likes != 3 || !p.LastRefresh.IsZero() {
+		t.Errorf("Unexpected project %+v", p)
+	}
+	if r, err := s.GetRoast("1"); err != nil || r.Content != "lol" {
+		t.Errorf("GetRoast() = %v, %v", r, err)
+	}
+	// The original file was backed up and rewritten.
+	if b, err := os.ReadFile(path + ".v1.bak"); err != nil || string(b) != storeV1 {
+		t.Errorf("Unexpected backup: %q, %v", b, err)
+	}
+	data := serializedCache{}
+	b, _ := os.ReadFile(path)
+	if err := json.Unmarshal(b, &data); err != nil || data.Version != storeVersion {
+		t.Errorf("Unexpected file: %q, %v", b, err)
+	}
+}
+
+func TestMigrateLogStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.log")
+	// Logs written before the version record was added.
+	v1 := `{"k":"event/e","v":{"id":"e","projects":[{"id":"1","last_refresh":"2025-07-01T00:00:00Z"}]}}` + "\n"
+	if err := os.WriteFile(path, []byte(v1), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewLogStore(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, err := s.GetProject("e", "1"); err != nil || !p.LastRefresh.IsZero() {
+		t.Errorf("GetProject() = %+v, %v", p, err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path + ".v1.bak"); err != nil {
+		t.Error(err)
+	}
+	// Reopening doesn't migrate again.
+	if err := os.Remove(path + ".v1.bak"); err != nil {
+		t.Fatal(err)
+	}
+	if s, err = NewLogStore(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if m, _ := filepath.Glob(path + ".*"); len(m) != 0 {
+		t.Errorf("Unexpected files: %q", m)
+	}
+}
+
+func TestNewerVersion(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "store.json")
+	if err := os.WriteFile(path, []byte(`{"version": 1000}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileStore(path, 0); !errors.Is(err, ErrNewerVersion) {
+		t.Errorf("Expected ErrNewerVersion, got %v", err)
+	}
+	path = filepath.Join(dir, "store.log")
+	if err := os.WriteFile(path, []byte(`{"k":"version","v":1000}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewLogStore(path, 0); !errors.Is(err, ErrNewerVersion) {
+		t.Errorf("Expected ErrNewerVersion, got %v", err)
+	}
+	// The files are left untouched.
+	if m, _ := filepath.Glob(filepath.Join(dir, "*")); len(m) != 2 {
+		t.Errorf("Unexpected files: %q", m)
+	}
+}
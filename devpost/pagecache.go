@@ -0,0 +1,164 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devpost
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"net/http"
+	"sync"
+)
+
+// maxPages is the default number of pages kept in the pageCache.
+const maxPages = 2048
+
+// pageCache remembers the pages fetched so unchanged pages are neither
+// downloaded nor parsed again.
+//
+// Only the validators, the body hash and the parsed value are kept, not the
+// body itself. The least recently used pages are evicted past size.
+type pageCache struct {
+	mu sync.Mutex
+	// size is the maximum number of pages kept. Defaults to maxPages.
+	size int
+	// pages maps the URLs to their element in lru.
+	pages map[string]*list.Element
+	// lru holds the *pageEntry, most recently used first.
+	lru    list.List
+	hits   int64
+	misses int64
+}
+
+type pageEntry struct {
+	url  string
+	page *page
+}
+
+// page is the last version seen of a URL.
+type page struct {
+	etag         string
+	lastModified string
+	hash         [sha256.Size]byte
+	// parsed is the result of parsing the body. It is shared and must not be
+	// modified.
+	parsed any
+}
+
+// pageStats counts the pages that were served from the page cache.
+type pageStats struct {
+	hits   int
+	misses int
+}
+
+func (c *pageCache) get(url string) *page {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.pages[url]
+	if e == nil {
+		return nil
+	}
+	c.lru.MoveToFront(e)
+	return e.Value.(*pageEntry).page
+}
+
+func (c *pageCache) put(url string, p *page, hit bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pages == nil {
+		c.pages = map[string]*list.Element{}
+	}
+	if e := c.pages[url]; e != nil {
+		e.Value.(*pageEntry).page = p
+		c.lru.MoveToFront(e)
+	} else {
+		c.pages[url] = c.lru.PushFront(&pageEntry{url: url, page: p})
+	}
+	size := c.size
+	if size <= 0 {
+		size = maxPages
+	}
+	for c.lru.Len() > size {
+		c.remove(c.lru.Back())
+	}
+	if hit {
+		c.hits++
+	} else {
+		c.misses++
+	}
+}
+
+// forgetEvent drops the pages hosted on the event's subdomain, e.g. when the
+// event is not refreshed anymore.
+func (c *pageCache) forgetEvent(eventID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for url, e := range c.pages {
+		if eventIDFromURL(url) == eventID {
+			c.remove(e)
+		}
+	}
+}
+
+// remove drops the page. c.mu must be held.
+func (c *pageCache) remove(e *list.Element) {
+	delete(c.pages, e.Value.(*pageEntry).url)
+	c.lru.Remove(e)
+}
+
+// hitRatio returns the ratio of pages that didn't need to be parsed since the
+// client was created.
+func (c *pageCache) hitRatio() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.hits+c.misses == 0 {
+		return 0
+	}
+	return float64(c.hits) / float64(c.hits+c.misses)
+}
+
+// fetchParsed fetches url and parses it, unless it is unchanged since the
+// previous call for the same url, in which case the previous result is
+// returned.
+//
+// The request is conditional when the previous response had an ETag or a
+// Last-Modified header. The returned value is shared and must not be
+// modified. hash is the SHA-256 of the body.
+func fetchParsed[T any](ctx context.Context, d *client, url string, st *pageStats, parse func(bod []byte) (T, error)) (v T, hash [sha256.Size]byte, err error) {
+	prev := d.pages.get(url)
+	if prev != nil {
+		if _, ok := prev.parsed.(T); !ok {
+			prev = nil
+		}
+	}
+	bod, resp, err := d.fetch(ctx, url, prev)
+	if err != nil {
+		return v, hash, err
+	}
+	next := &page{etag: resp.Header.Get("ETag"), lastModified: resp.Header.Get("Last-Modified")}
+	if resp.StatusCode == http.StatusNotModified {
+		// fetch only sends validators when prev is set.
+		next.etag = prev.etag
+		next.lastModified = prev.lastModified
+		next.hash = prev.hash
+	} else {
+		next.hash = sha256.Sum256(bod)
+	}
+	hit := prev != nil && prev.hash == next.hash
+	if hit {
+		next.parsed = prev.parsed
+	} else if next.parsed, err = parse(bod); err != nil {
+		return v, hash, err
+	}
+	d.pages.put(url, next, hit)
+	if st != nil {
+		if hit {
+			st.hits++
+		} else {
+			st.misses++
+		}
+	}
+	return next.parsed.(T), next.hash, nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devpost
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// pageServer serves body, with an ETag if etag is set.
+type pageServer struct {
+	mu          sync.Mutex
+	body        string
+	etag        string
+	requests    int
+	notModified int
+}
+
+func (s *pageServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	if s.etag != "" {
+		if r.Header.Get("If-None-Match") == s.etag {
+			s.notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", s.etag)
+	}
+	_, _ = w.Write([]byte(s.body))
+}
+
+func (s *pageServer) set(body, etag string) {
+	s.mu.Lock()
+	s.body = body
+	s.etag = etag
+	s.mu.Unlock()
+}
+
+func TestFetchParsed(t *testing.T) {
+	for _, etag := range []string{"", `"v1"`} {
+		t.Run(fmt.Sprintf("etag=%q", etag), func(t *testing.T) {
+			s := &pageServer{body: "hello", etag: etag}
+			d, _ := newTestClient(t, s)
+			parses := 0
+			parse := func(bod []byte) (string, error) {
+				parses++
+				return string(bod), nil
+			}
+			var st pageStats
+			for range 3 {
+				v, _, err := fetchParsed(t.Context(), d, "https://devpost.com/page", &st, parse)
+				if err != nil || v != "hello" {
+					t.Fatalf("fetchParsed() = %q, %v", v, err)
+				}
+			}
+			if parses != 1 || st.hits != 2 || st.misses != 1 {
+				t.Errorf("Unexpected parses %d, stats %+v", parses, st)
+			}
+			if etag != "" && s.notModified != 2 {
+				t.Errorf("Expected 2 304s, got %d", s.notModified)
+			}
+
+			// A new body is parsed again.
+			if etag != "" {
+				etag = `"v2"`
+			}
+			s.set("world", etag)
+			if v, _, err := fetchParsed(t.Context(), d, "https://devpost.com/page", &st, parse); err != nil || v != "world" {
+				t.Fatalf("fetchParsed() = %q, %v", v, err)
+			}
+			if parses != 2 || s.requests != 4 {
+				t.Errorf("Unexpected parses %d, requests %d", parses, s.requests)
+			}
+			if r := d.pages.hitRatio(); r != 0.5 {
+				t.Errorf("Unexpected hit ratio %g", r)
+			}
+		})
+	}
+}
+
+func TestCachedClientUnchangedGallery(t *testing.T) {
+	d, _ := newTestClient(t, &flakyServer{})
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "store.json"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	cd, err := NewCached(t.Context(), d, time.Hour, 30*time.Minute, store, SchedulerOptions{Clock: &fakeClock{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cd.Close()
+	c := cd.(*cachedClient)
+	first, err := c.fetchProjects(t.Context(), "e")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.mu.Lock()
+	hash := c.events["e"].GalleryHash
+	// Tweak the cached copy to detect whether the gallery is merged again.
+	c.update("e", func(e *Event) {
+		e.Projects = slices.Clone(e.Projects)
+		p := *e.Projects[0]
+		p.Title = "Tweaked"
+		e.Projects[0] = &p
+	})
+	c.mu.Unlock()
+	if len(first) != 2 || hash == "" {
+		t.Fatalf("Unexpected projects %d, hash %q", len(first), hash)
+	}
+	second, err := c.fetchProjects(t.Context(), "e")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(second) != 2 || second[0].Title != "Tweaked" {
+		t.Errorf("Expected the gallery to not be merged, got %+v", second[0])
+	}
+}
+
+func TestPageCacheEvict(t *testing.T) {
+	c := pageCache{size: 3}
+	urls := []string{"https://a.devpost.com/", "https://a.devpost.com/prizes", "https://devpost.com/software/x", "https://b.devpost.com/"}
+	for _, u := range urls[:3] {
+		c.put(u, &page{parsed: u}, false)
+	}
+	// Use the first page so the second one is the least recently used.
+	if c.get(urls[0]) == nil {
+		t.Fatal("expected a page")
+	}
+	c.put(urls[3], &page{parsed: urls[3]}, false)
+	if c.get(urls[1]) != nil || c.get(urls[0]) == nil || c.get(urls[3]) == nil {
+		t.Errorf("Unexpected eviction, %d pages", len(c.pages))
+	}
+	c.forgetEvent("a")
+	if c.get(urls[0]) != nil || c.get(urls[2]) == nil || c.get(urls[3]) == nil || c.lru.Len() != 2 {
+		t.Errorf("Unexpected pages after forgetEvent, %d pages", len(c.pages))
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devpost
+
+import (
+	"cmp"
+	"io"
+	"maps"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maruel/devpostdash/dom"
+	"golang.org/x/net/html"
+)
+
+// Profile is the data scraped from a devpost profile page.
+type Profile struct {
+	Name      string   `json:"name"`
+	AvatarURL string   `json:"avatar_url"`
+	Location  string   `json:"location"`
+	Skills    []string `json:"skills"`
+	// Hackathons is the number of hackathons the person took part in.
+	Hackathons int `json:"hackathons"`
+
+	LastRefresh time.Time `json:"last_refresh,omitzero"`
+}
+
+// ParticipantProject is a project a participant is a team member of.
+type ParticipantProject struct {
+	EventID   string `json:"event_id"`
+	ProjectID string `json:"project_id"`
+	Title     string `json:"title"`
+	URL       string `json:"url"`
+	// Role is what the person did in the project.
+	Role   string `json:"role,omitempty"`
+	Winner bool   `json:"winner"`
+	Likes  int    `json:"likes"`
+}
+
+// Participant is a person with their projects across the events.
+type Participant struct {
+	// Person is the person as listed in the teams. Role is not set, see
+	// ParticipantProject.Role.
+	Person
+	Username string               `json:"username"`
+	Projects []ParticipantProject `json:"projects"`
+	// Profile is scraped from the profile page, when available.
+	Profile *Profile `json:"profile,omitempty"`
+}
+
+var reUsername = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// Username returns the username of a devpost profile URL, or "" if it isn't
+// one.
+func Username(profileURL string) string {
+	u, err := url.Parse(profileURL)
+	if err != nil || !strings.EqualFold(u.Host, "devpost.com") {
+		return ""
+	}
+	name := strings.Trim(u.Path, "/")
+	if !reUsername.MatchString(name) {
+		return ""
+	}
+	return name
+}
+
+// peopleIndex maps the people to their projects. The participants are
+// immutable snapshots like the events.
+type peopleIndex struct {
+	// events maps the event ID to its participants, keyed by username in
+	// lower case.
+	events map[string]map[string]*Participant
+}
+
+// index rebuilds the participants of the event.
+func (x *peopleIndex) index(e *Event) {
+	if x.events == nil {
+		x.events = map[string]map[string]*Participant{}
+	}
+	people := map[string]*Participant{}
+	for _, p := range e.Projects {
+		for _, m := range p.Team {
+			name := Username(m.URL)
+			if name == "" {
+				continue
+			}
+			key := strings.ToLower(name)
+			pp := people[key]
+			if pp == nil {
+				pp = &Participant{Person: m, Username: name}
+				pp.Role = ""
+				people[key] = pp
+			} else if pp.AvatarURL == "" {
+				pp.AvatarURL = m.AvatarURL
+			}
+			pp.Projects = append(pp.Projects, ParticipantProject{
+				EventID:   e.ID,
+				ProjectID: p.ID,
+				Title:     p.Title,
+				URL:       p.URL,
+				Role:      m.Role,
+				Winner:    p.Winner,
+				Likes:     p.Likes,
+			})
+		}
+	}
+	x.events[e.ID] = people
+}
+
+// event returns copies of the participants of the event, sorted by name.
+func (x *peopleIndex) event(eventID string) []*Participant {
+	out := make([]*Participant, 0, len(x.events[eventID]))
+	for _, p := range x.events[eventID] {
+		c := *p
+		c.Projects = slices.Clone(p.Projects)
+		out = append(out, &c)
+	}
+	slices.SortFunc(out, func(a, b *Participant) int {
+		return cmp.Or(cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)), cmp.Compare(a.Username, b.Username))
+	})
+	return out
+}
+
+// person returns a copy of the participant with their projects in all the
+// events, or nil if the person isn't in any team.
+func (x *peopleIndex) person(username string) *Participant {
+	key := strings.ToLower(username)
+	var out *Participant
+	for _, eventID := range slices.Sorted(maps.Keys(x.events)) {
+		p := x.events[eventID][key]
+		if p == nil {
+			continue
+		}
+		if out == nil {
+			c := *p
+			c.Projects = nil
+			out = &c
+		}
+		out.Projects = append(out.Projects, p.Projects...)
+	}
+	return out
+}
+
+// parseProfile parses a devpost profile page.
+func parseProfile(r io.Reader) (*Profile, error) {
+	doc, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &Profile{}
+	if n := dom.FirstChild(doc, dom.ID("portfolio-user-name")); n != nil {
+		p.Name = getName(n)
+	}
+	if n := dom.FirstChild(doc, dom.ID("portfolio-user-photo")); n != nil {
+		if img := dom.FirstChild(n, dom.Tag("img")); img != nil {
+			p.AvatarURL = normalizeURL(dom.NodeAttr(img, "src"))
+		}
+	}
+	if n := dom.FirstChild(doc, dom.Class("ss-location")); n != nil && n.Parent != nil {
+		p.Location = dom.NodeText(n.Parent)
+	}
+	if n := dom.FirstChild(doc, dom.ID("portfolio-user-tags")); n != nil {
+		for t := range dom.YieldChildren(n, dom.Class("cp-tag")) {
+			if s := dom.NodeText(t); s != "" {
+				p.Skills = append(p.Skills, s)
+			}
+		}
+	}
+	for a := range dom.YieldChildren(doc, dom.Tag("a")) {
+		if !strings.HasSuffix(dom.NodeAttr(a, "href"), "/challenges") {
+			continue
+		}
+		if f := strings.Fields(dom.NodeText(a)); len(f) != 0 {
+			if i, err := strconv.Atoi(strings.ReplaceAll(f[0], ",", "")); err == nil {
+				p.Hackathons = i
+				break
+			}
+		}
+	}
+	return p, nil
+}
+
+// getName returns the text of n without the text of its <small> children,
+// which hold the username.
+func getName(n *html.Node) string {
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Data == "small" {
+			continue
+		}
+		if c.Type == html.TextNode {
+			b.WriteString(c.Data)
+		} else {
+			b.WriteString(dom.NodeText(c))
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
@@ -0,0 +1,149 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devpost
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestUsername(t *testing.T) {
+	for in, want := range map[string]string{
+		"https://devpost.com/alice":          "alice",
+		"https://devpost.com/Bob_42/":        "Bob_42",
+		"https://devpost.com/software/thing": "",
+		"https://example.com/alice":          "",
+		"":                                   "",
+	} {
+		if got := Username(in); got != want {
+			t.Errorf("Username(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestParseProfile(t *testing.T) {
+	const page = `<html><body>
+<div id="portfolio-user-photo"><img src="//example.com/alice.png"></div>
+<h1 id="portfolio-user-name">Alice Liddell <small>(alice)</small></h1>
+<ul id="portfolio-user-links"><li><span class="ss-icon ss-location"></span> Oxford, UK</li></ul>
+<div id="portfolio-user-tags"><span class="cp-tag">go</span><span class="cp-tag">rust</span></div>
+<div class="cp-tag">not a skill</div>
+<nav><a href="/alice"><span>3</span> Projects</a><a href="/alice/challenges"><span>1,024</span> Hackathons</a></nav>
+</body></html>`
+	p, err := parseProfile(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "Alice Liddell" || p.AvatarURL != "https://example.com/alice.png" || p.Location != "Oxford, UK" || p.Hackathons != 1024 {
+		t.Errorf("Unexpected profile %+v", p)
+	}
+	if !slices.Equal(p.Skills, []string{"go", "rust"}) {
+		t.Errorf("Skills = %q", p.Skills)
+	}
+}
+
+func TestPeopleIndex(t *testing.T) {
+	var x peopleIndex
+	alice := Person{Name: "Alice", URL: "https://devpost.com/alice"}
+	x.index(&Event{ID: "e1", Projects: []*Project{
+		{ID: "1", Title: "One", Team: []Person{{Name: "Alice", URL: "https://devpost.com/alice", Role: "Backend"}, {Name: "Bob", URL: "https://devpost.com/bob/"}}},
+		{ID: "2", Title: "Two", Winner: true, Team: []Person{alice, {Name: "You", URL: "https://example.com"}}},
+	}})
+	x.index(&Event{ID: "e2", Projects: []*Project{
+		{ID: "3", Title: "Three", Team: []Person{{Name: "Alice", URL: "https://devpost.com/Alice", AvatarURL: "a.png"}}},
+	}})
+	people := x.event("e1")
+	var names []string
+	for _, p := range people {
+		names = append(names, p.Username)
+	}
+	if !slices.Equal(names, []string{"alice", "bob"}) {
+		t.Fatalf("Unexpected people %v", names)
+	}
+	if p := people[0]; len(p.Projects) != 2 || p.Projects[0].Role != "Backend" || !p.Projects[1].Winner || p.Role != "" {
+		t.Errorf("Unexpected participant %+v", p)
+	}
+	p := x.person("ALICE")
+	if p == nil || len(p.Projects) != 3 || p.Projects[2].EventID != "e2" {
+		t.Fatalf("Unexpected participant %+v", p)
+	}
+	// Reindexing replaces the event's participants.
+	x.index(&Event{ID: "e2"})
+	if p := x.person("alice"); len(p.Projects) != 2 {
+		t.Errorf("Unexpected participant %+v", p)
+	}
+	if p := x.person("carol"); p != nil {
+		t.Errorf("Unexpected participant %+v", p)
+	}
+}
+
+// profileClient serves a profile for alice only.
+type profileClient struct {
+	fakeClient
+	fetches atomic.Int32
+}
+
+func (p *profileClient) FetchPerson(ctx context.Context, username string) (*Participant, error) {
+	p.fetches.Add(1)
+	if username != "alice" {
+		return nil, &HTTPError{StatusCode: http.StatusNotFound}
+	}
+	return &Participant{Person: Person{Name: "Alice L."}, Username: username, Profile: &Profile{Name: "Alice L.", Location: "Oxford"}}, nil
+}
+
+func TestCachedClientPeople(t *testing.T) {
+	store, err := NewFileStore(filepath.Join(t.TempDir(), "store.json"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	f := &profileClient{}
+	d, err := NewCached(t.Context(), f, time.Hour, 30*time.Minute, store, SchedulerOptions{Clock: &fakeClock{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	people, err := d.FetchPeople(t.Context(), "e")
+	if err != nil || len(people) != 1 || people[0].Username != "alice" || len(people[0].Projects) != 10 {
+		t.Fatalf("FetchPeople() = %+v, %v", people, err)
+	}
+	for range 2 {
+		p, err := d.FetchPerson(t.Context(), "alice")
+		if err != nil || len(p.Projects) != 10 || p.Profile == nil || p.Profile.Location != "Oxford" {
+			t.Fatalf("FetchPerson() = %+v, %v", p, err)
+		}
+	}
+	// A person not in a team is not looked up on devpost.
+	var herr *HTTPError
+	for _, name := range []string{"bob", "hackathons", ".."} {
+		if _, err := d.FetchPerson(t.Context(), name); !errors.As(err, &herr) || herr.StatusCode != http.StatusNotFound {
+			t.Errorf("%s: Expected a 404, got %v", name, err)
+		}
+	}
+	if n := f.fetches.Load(); n != 1 {
+		t.Errorf("Expected the profiles to be cached, got %d fetches", n)
+	}
+
+	// The profiles expire.
+	c := d.(*cachedClient)
+	c.mu.Lock()
+	e := c.profiles["alice"]
+	e.fetched = e.fetched.Add(-profileTTL)
+	c.profiles["alice"] = e
+	c.mu.Unlock()
+	if _, err := d.FetchPerson(t.Context(), "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if n := f.fetches.Load(); n != 2 {
+		t.Errorf("Expected the expired profile to be fetched again, got %d fetches", n)
+	}
+}
@@ -0,0 +1,330 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devpost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RetryPolicy controls how requests to devpost are retried. The fields left
+// to zero take their value from DefaultRetryPolicy.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts per request.
+	MaxAttempts int
+	// Backoff is the initial delay between attempts, doubled after each
+	// failure up to MaxBackoff. Up to 25% of jitter is added.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// MaxRetryAfter is the longest Retry-After honored. The request fails
+	// instead of waiting longer.
+	MaxRetryAfter time.Duration
+	// Timeout is the deadline of each attempt.
+	Timeout time.Duration
+	// Deadline bounds the whole request, including the waits between the
+	// attempts. No attempt is made past it.
+	Deadline time.Duration
+	// BreakerThreshold is the number of consecutive transient failures that
+	// opens the circuit breaker.
+	BreakerThreshold int
+	// BreakerCooldown is how long the circuit breaker stays open, doubled
+	// each time the probe request fails, up to 32 times.
+	BreakerCooldown time.Duration
+}
+
+// DefaultRetryPolicy is the RetryPolicy used by default by New.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:      4,
+	Backoff:          time.Second,
+	MaxBackoff:       30 * time.Second,
+	MaxRetryAfter:    2 * time.Minute,
+	Timeout:          30 * time.Second,
+	Deadline:         3 * time.Minute,
+	BreakerThreshold: 5,
+	BreakerCooldown:  30 * time.Second,
+}
+
+// withDefaults returns the policy with the zero fields set from
+// DefaultRetryPolicy.
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	d := &DefaultRetryPolicy
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = d.MaxAttempts
+	}
+	if p.Backoff <= 0 {
+		p.Backoff = d.Backoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = d.MaxBackoff
+	}
+	if p.MaxRetryAfter <= 0 {
+		p.MaxRetryAfter = d.MaxRetryAfter
+	}
+	if p.Timeout <= 0 {
+		p.Timeout = d.Timeout
+	}
+	if p.Deadline <= 0 {
+		p.Deadline = d.Deadline
+	}
+	if p.BreakerThreshold <= 0 {
+		p.BreakerThreshold = d.BreakerThreshold
+	}
+	if p.BreakerCooldown <= 0 {
+		p.BreakerCooldown = d.BreakerCooldown
+	}
+	return p
+}
+
+// BreakerState is the state of the circuit breaker.
+type BreakerState string
+
+const (
+	// BreakerClosed means requests flow normally.
+	BreakerClosed BreakerState = "closed"
+	// BreakerOpen means requests fail immediately until OpenUntil.
+	BreakerOpen BreakerState = "open"
+	// BreakerHalfOpen means one probe request is allowed through.
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+// UpstreamStatus is the health of devpost as seen by the client.
+type UpstreamStatus struct {
+	State BreakerState `json:"state"`
+	// RateLimited is true when the last failure was a 429.
+	RateLimited         bool      `json:"rate_limited"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	OpenUntil           time.Time `json:"open_until,omitzero"`
+	LastStatusCode      int       `json:"last_status_code,omitempty"`
+	LastError           string    `json:"last_error,omitempty"`
+	LastFailure         time.Time `json:"last_failure,omitzero"`
+}
+
+// ErrCircuitOpen is returned without contacting devpost while the circuit
+// breaker is open.
+var ErrCircuitOpen = errors.New("devpost is unavailable, backing off")
+
+// breaker is a circuit breaker shared by all the requests to devpost.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu      sync.Mutex
+	status  UpstreamStatus
+	opened  int
+	probing bool
+}
+
+// allow returns ErrCircuitOpen if the request must not be sent.
+func (b *breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state() {
+	case BreakerOpen:
+		return fmt.Errorf("%w until %s", ErrCircuitOpen, b.status.OpenUntil.Format(time.TimeOnly))
+	case BreakerHalfOpen:
+		if b.probing {
+			return fmt.Errorf("%w, probing", ErrCircuitOpen)
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// state returns the current state. b.mu must be held.
+func (b *breaker) state() BreakerState {
+	if b.status.OpenUntil.IsZero() {
+		return BreakerClosed
+	}
+	if b.now().Before(b.status.OpenUntil) {
+		return BreakerOpen
+	}
+	return BreakerHalfOpen
+}
+
+// success records a response that is not a transient failure.
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.status.OpenUntil.IsZero() {
+		slog.Info("devpost", "msg", "circuit breaker closed")
+	}
+	b.status.ConsecutiveFailures = 0
+	b.status.OpenUntil = time.Time{}
+	b.status.RateLimited = false
+	b.opened = 0
+	b.probing = false
+}
+
+// failure records a transient failure. retryAfter is the delay requested by
+// the server, if any.
+func (b *breaker) failure(statusCode int, err error, retryAfter time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	b.status.ConsecutiveFailures++
+	b.status.RateLimited = statusCode == http.StatusTooManyRequests
+	b.status.LastStatusCode = statusCode
+	b.status.LastError = err.Error()
+	b.status.LastFailure = now
+	if b.probing || b.status.ConsecutiveFailures >= b.threshold || retryAfter > 0 {
+		// Honor Retry-After for everyone.
+		cooldown := retryAfter
+		if cooldown == 0 {
+			cooldown = b.cooldown << min(b.opened, 5)
+		}
+		if until := now.Add(cooldown); until.After(b.status.OpenUntil) {
+			b.status.OpenUntil = until
+		}
+		b.opened++
+		slog.Warn("devpost", "msg", "circuit breaker open", "until", b.status.OpenUntil, "status", statusCode, "err", err)
+	}
+	b.probing = false
+}
+
+// abort releases the probe slot without recording a result.
+func (b *breaker) abort() {
+	b.mu.Lock()
+	b.probing = false
+	b.mu.Unlock()
+}
+
+func (b *breaker) upstream() *UpstreamStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := b.status
+	s.State = b.state()
+	return &s
+}
+
+// get fetches the URL, retrying transient failures.
+func (d *client) get(ctx context.Context, url string) ([]byte, error) {
+	bod, _, err := d.fetch(ctx, url, nil)
+	return bod, err
+}
+
+// fetch is like get but makes the request conditional on the validators of
+// prev, if set. resp is the last response received; its body is already
+// consumed. The body is nil when the status is 304.
+func (d *client) fetch(ctx context.Context, url string, prev *page) ([]byte, *http.Response, error) {
+	delay := d.retry.Backoff
+	deadline := d.breaker.now().Add(d.retry.Deadline)
+	for attempt := 1; ; attempt++ {
+		if err := d.breaker.allow(); err != nil {
+			return nil, nil, err
+		}
+		timeout := min(d.retry.Timeout, deadline.Sub(d.breaker.now()))
+		bod, resp, err := d.getOnce(ctx, url, prev, timeout)
+		if err == nil {
+			d.breaker.success()
+			return bod, resp, nil
+		}
+		if ctx.Err() != nil {
+			// The caller gave up, it says nothing about devpost's health.
+			d.breaker.abort()
+			return nil, nil, err
+		}
+		statusCode := 0
+		retryAfter := time.Duration(0)
+		if resp != nil {
+			statusCode = resp.StatusCode
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		}
+		if !isTransient(statusCode) {
+			d.breaker.success()
+			return bod, resp, err
+		}
+		d.breaker.failure(statusCode, err, retryAfter)
+		if attempt >= d.retry.MaxAttempts || retryAfter > d.retry.MaxRetryAfter {
+			return bod, resp, err
+		}
+		// Add up to 25% of jitter.
+		wait := max(delay+rand.N(delay/4+1), retryAfter)
+		if d.breaker.now().Add(wait).After(deadline) {
+			slog.InfoContext(ctx, "devpost", "msg", "giving up", "url", url, "attempt", attempt, "err", err)
+			return bod, resp, err
+		}
+		slog.InfoContext(ctx, "devpost", "msg", "retrying", "url", url, "attempt", attempt, "wait", wait, "err", err)
+		if err := d.sleep(ctx, wait); err != nil {
+			return nil, nil, err
+		}
+		delay = min(2*delay, d.retry.MaxBackoff)
+	}
+}
+
+// getOnce does one attempt. resp is nil on network errors.
+func (d *client) getOnce(ctx context.Context, url string, prev *page, timeout time.Duration) ([]byte, *http.Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, &http.Response{StatusCode: http.StatusBadRequest}, err
+	}
+	if prev != nil {
+		if prev.etag != "" {
+			req.Header.Set("If-None-Match", prev.etag)
+		}
+		if prev.lastModified != "" {
+			req.Header.Set("If-Modified-Since", prev.lastModified)
+		}
+	}
+	resp, err := d.c.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	bod, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotModified && prev != nil:
+		return nil, resp, nil
+	case resp.StatusCode != 200:
+		return bod, resp, &HTTPError{StatusCode: resp.StatusCode, Body: bod}
+	}
+	return bod, resp, nil
+}
+
+// isTransient returns true if the request may succeed when retried.
+// statusCode 0 is a network error.
+func isTransient(statusCode int) bool {
+	return statusCode == 0 || statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// parseRetryAfter parses the Retry-After header, either in seconds or as a
+// HTTP date. It returns 0 when absent or invalid.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if s, err := strconv.Atoi(v); err == nil {
+		return max(time.Duration(s)*time.Second, 0)
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(t.Sub(now), 0)
+	}
+	return 0
+}
+
+// sleep waits for d or until the context is canceled.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devpost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+// rewriteTransport sends all the requests to the test server.
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (r *rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = r.target.Scheme
+	req.URL.Host = r.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// flakyServer fails the requests as long as there are failures queued.
+type flakyServer struct {
+	mu       sync.Mutex
+	failures []func(w http.ResponseWriter)
+	// failPage fails the first request for this gallery page.
+	failPage string
+	requests int
+}
+
+func (f *flakyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.requests++
+	var fail func(w http.ResponseWriter)
+	if len(f.failures) != 0 {
+		fail = f.failures[0]
+		f.failures = f.failures[1:]
+	}
+	if p := r.URL.Query().Get("page"); p != "" && p == f.failPage {
+		fail = status(http.StatusServiceUnavailable)
+		f.failPage = ""
+	}
+	f.mu.Unlock()
+	if fail != nil {
+		fail(w)
+		return
+	}
+	if r.URL.Path == "/project-gallery" {
+		// Two pages of results.
+		if p := r.URL.Query().Get("page"); p == "1" || p == "2" {
+			fmt.Fprintf(w, `<div id="submission-gallery"><div class="gallery-item" data-software-id="%s"><a class="block-wrapper-link" href="https://devpost.com/software/p%s"><h5>Project %s</h5></a></div></div>`, p, p, p)
+		}
+		return
+	}
+	_, _ = w.Write([]byte("ok"))
+}
+
+func status(code int, header ...string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		for i := 0; i < len(header); i += 2 {
+			w.Header().Set(header[i], header[i+1])
+		}
+		w.WriteHeader(code)
+	}
+}
+
+func newTestClient(t *testing.T, h http.Handler) (*client, *[]time.Duration) {
+	ts := httptest.NewServer(h)
+	t.Cleanup(ts.Close)
+	u, _ := url.Parse(ts.URL)
+	d := newClient(&rewriteTransport{target: u}, ClientOptions{Retry: RetryPolicy{
+		MaxAttempts:      3,
+		Backoff:          time.Second,
+		MaxBackoff:       4 * time.Second,
+		MaxRetryAfter:    time.Minute,
+		Timeout:          5 * time.Second,
+		Deadline:         time.Minute,
+		BreakerThreshold: 3,
+		BreakerCooldown:  10 * time.Second,
+	}})
+	// Fake time.
+	now := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	var sleeps []time.Duration
+	d.breaker.now = func() time.Time { return now }
+	d.sleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		now = now.Add(d)
+		return nil
+	}
+	return d, &sleeps
+}
+
+func TestGetRetry(t *testing.T) {
+	f := &flakyServer{failures: []func(http.ResponseWriter){status(503), status(502)}}
+	d, sleeps := newTestClient(t, f)
+	b, err := d.get(t.Context(), "https://devpost.com/")
+	if err != nil || string(b) != "ok" {
+		t.Fatalf("get() = %q, %v", b, err)
+	}
+	if f.requests != 3 || len(*sleeps) != 2 {
+		t.Errorf("Unexpected requests %d, sleeps %v", f.requests, *sleeps)
+	}
+	// Exponential backoff with up to 25% jitter.
+	if s := *sleeps; s[0] < time.Second || s[0] > 1250*time.Millisecond || s[1] < 2*time.Second || s[1] > 2500*time.Millisecond {
+		t.Errorf("Unexpected sleeps %v", s)
+	}
+}
+
+func TestGetRetryDeadline(t *testing.T) {
+	f := &flakyServer{failures: []func(http.ResponseWriter){status(503), status(503), status(503), status(503)}}
+	d, sleeps := newTestClient(t, f)
+	d.retry.MaxAttempts = 10
+	d.retry.Deadline = 5 * time.Second
+	// The third wait of at least 4s would end past the deadline.
+	var herr *HTTPError
+	if _, err := d.get(t.Context(), "https://devpost.com/"); !errors.As(err, &herr) || herr.StatusCode != 503 {
+		t.Fatalf("Expected 503, got %v", err)
+	}
+	if f.requests != 3 || len(*sleeps) != 2 {
+		t.Errorf("Unexpected requests %d, sleeps %v", f.requests, *sleeps)
+	}
+}
+
+func TestGetRetryAfter(t *testing.T) {
+	f := &flakyServer{failures: []func(http.ResponseWriter){status(429, "Retry-After", "7")}}
+	d, sleeps := newTestClient(t, f)
+	if _, err := d.get(t.Context(), "https://devpost.com/"); err != nil {
+		t.Fatal(err)
+	}
+	if s := *sleeps; len(s) != 1 || s[0] != 7*time.Second {
+		t.Errorf("Unexpected sleeps %v", s)
+	}
+	if s, _ := d.Upstream(t.Context()); s.State != BreakerClosed || s.LastStatusCode != 429 {
+		t.Errorf("Unexpected status %+v", s)
+	}
+
+	// Too long to wait.
+	f.failures = []func(http.ResponseWriter){status(429, "Retry-After", "3600")}
+	var herr *HTTPError
+	if _, err := d.get(t.Context(), "https://devpost.com/"); !errors.As(err, &herr) || herr.StatusCode != 429 {
+		t.Errorf("Expected 429, got %v", err)
+	}
+	if s, _ := d.Upstream(t.Context()); s.State != BreakerOpen || !s.RateLimited {
+		t.Errorf("Unexpected status %+v", s)
+	}
+}
+
+func TestGetNoRetry(t *testing.T) {
+	f := &flakyServer{failures: []func(http.ResponseWriter){status(404)}}
+	d, sleeps := newTestClient(t, f)
+	var herr *HTTPError
+	if _, err := d.get(t.Context(), "https://devpost.com/"); !errors.As(err, &herr) || herr.StatusCode != 404 {
+		t.Errorf("Expected 404, got %v", err)
+	}
+	if f.requests != 1 || len(*sleeps) != 0 {
+		t.Errorf("Unexpected requests %d, sleeps %v", f.requests, *sleeps)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	f := &flakyServer{}
+	for range 3 {
+		f.failures = append(f.failures, status(500))
+	}
+	d, _ := newTestClient(t, f)
+	d.retry.MaxAttempts = 1
+	for range 3 {
+		if _, err := d.get(t.Context(), "https://devpost.com/"); err == nil {
+			t.Fatal("Expected error")
+		}
+	}
+	// devpost is not contacted while the breaker is open.
+	if _, err := d.get(t.Context(), "https://devpost.com/"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if f.requests != 3 {
+		t.Errorf("Expected 3 requests, got %d", f.requests)
+	}
+	s, _ := d.Upstream(t.Context())
+	if s.State != BreakerOpen || s.ConsecutiveFailures != 3 || s.RateLimited {
+		t.Errorf("Unexpected status %+v", s)
+	}
+
+	// After the cooldown, a probe closes it.
+	_ = d.sleep(t.Context(), 10*time.Second)
+	if s, _ = d.Upstream(t.Context()); s.State != BreakerHalfOpen {
+		t.Errorf("Unexpected status %+v", s)
+	}
+	if _, err := d.get(t.Context(), "https://devpost.com/"); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ = d.Upstream(t.Context()); s.State != BreakerClosed || s.ConsecutiveFailures != 0 {
+		t.Errorf("Unexpected status %+v", s)
+	}
+}
+
+func TestFetchProjectsRetryPage(t *testing.T) {
+	f := &flakyServer{failPage: "2"}
+	d, sleeps := newTestClient(t, f)
+	g, err := d.fetchProjectsNew(t.Context(), "e", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	projects := g.projects
+	// The walk resumes at the failed page instead of dropping the rest.
+	if len(projects) != 2 || projects[0].ID != "1" || projects[1].ID != "2" {
+		t.Errorf("Unexpected projects %+v", projects)
+	}
+	if f.requests != 4 || len(*sleeps) != 1 {
+		t.Errorf("Unexpected requests %d, sleeps %v", f.requests, *sleeps)
+	}
+}
+
+func TestRetryPolicyDefaults(t *testing.T) {
+	d := newClient(http.DefaultTransport, ClientOptions{Retry: RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond}})
+	want := DefaultRetryPolicy
+	want.MaxAttempts = 2
+	want.Backoff = time.Millisecond
+	if d.retry != want {
+		t.Errorf("got %+v, want %+v", d.retry, want)
+	}
+	if d.breaker.threshold != want.BreakerThreshold || d.breaker.cooldown != want.BreakerCooldown {
+		t.Errorf("Unexpected breaker %d, %s", d.breaker.threshold, d.breaker.cooldown)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		in   string
+		want time.Duration
+	}{
+		{"", 0},
+		{"120", 2 * time.Minute},
+		{"-1", 0},
+		{"Tue, 01 Jul 2025 00:00:30 GMT", 30 * time.Second},
+		{"soon", 0},
+	} {
+		if got := parseRetryAfter(tc.in, now); got != tc.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,326 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devpost
+
+import (
+	"container/heap"
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Clock is the source of time of the Scheduler. It is replaced in tests.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// TaskKind is the kind of refresh done by a ScheduledTask.
+type TaskKind string
+
+const (
+	// TaskInfo refreshes the event's landing page.
+	TaskInfo TaskKind = "info"
+	// TaskProjects refreshes the event's project gallery.
+	TaskProjects TaskKind = "projects"
+	// TaskProject refreshes one project page.
+	TaskProject TaskKind = "project"
+)
+
+// ScheduledTask is a refresh queued in the Scheduler.
+type ScheduledTask struct {
+	Kind      TaskKind `json:"kind"`
+	EventID   string   `json:"event_id"`
+	ProjectID string   `json:"project_id,omitempty"`
+	// Host is the budget the task counts against, usually the host it
+	// fetches from. Hosts sharing a throttle must share a budget.
+	Host string    `json:"host"`
+	Due  time.Time `json:"due"`
+	// Weight is how much the event is being looked at, see Event.weight.
+	Weight   float64   `json:"weight"`
+	Running  bool      `json:"running,omitempty"`
+	LastRun  time.Time `json:"last_run,omitzero"`
+	LastErr  string    `json:"last_err,omitempty"`
+	Failures int       `json:"failures,omitempty"`
+
+	// index is the position in the heap, -1 when not queued.
+	index int
+	// next is set when the task is rescheduled while running.
+	next *ScheduledTask
+}
+
+func (t *ScheduledTask) key() string {
+	return string(t.Kind) + "/" + t.EventID + "/" + t.ProjectID
+}
+
+// taskQueue is a min-heap of tasks ordered by due time. Ties are broken by
+// weight.
+type taskQueue []*ScheduledTask
+
+func (q taskQueue) Len() int { return len(q) }
+
+func (q taskQueue) Less(i, j int) bool {
+	if !q[i].Due.Equal(q[j].Due) {
+		return q[i].Due.Before(q[j].Due)
+	}
+	return q[i].Weight > q[j].Weight
+}
+
+func (q taskQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *taskQueue) Push(x any) {
+	t := x.(*ScheduledTask)
+	t.index = len(*q)
+	*q = append(*q, t)
+}
+
+func (q *taskQueue) Pop() any {
+	old := *q
+	t := old[len(old)-1]
+	old[len(old)-1] = nil
+	t.index = -1
+	*q = old[:len(old)-1]
+	return t
+}
+
+// SchedulerOptions configures a Scheduler. The zero value is valid.
+type SchedulerOptions struct {
+	// Concurrency is the number of tasks run concurrently. Defaults to 1.
+	Concurrency int
+	// QPS is the maximum number of tasks started per second for each host.
+	// It should not be higher than the throttle of the underlying client.
+	// A task that fetches multiple pages still counts as one. Defaults to 1.
+	QPS float64
+	// Backoff is the delay before retrying a failed task, doubled on each
+	// consecutive failure up to 32 times. Defaults to 1 minute.
+	Backoff time.Duration
+	// Clock defaults to the wall clock.
+	Clock Clock
+}
+
+// Scheduler runs refresh tasks when they are due.
+//
+// Tasks are keyed by kind, event ID and project ID; scheduling a task that is
+// already queued updates its due time. A task that succeeds is dropped unless
+// it was rescheduled while running. A task that fails is retried with
+// exponential backoff.
+type Scheduler struct {
+	opts SchedulerOptions
+	run  func(ctx context.Context, t *ScheduledTask) error
+
+	mu    sync.Mutex
+	queue taskQueue
+	tasks map[string]*ScheduledTask
+	// hosts is the earliest time a task can be started for each host.
+	hosts map[string]time.Time
+	// wake is closed and replaced whenever the queue changes.
+	wake chan struct{}
+}
+
+// NewScheduler returns a Scheduler calling run for each due task. Call Run to
+// start it.
+func NewScheduler(opts SchedulerOptions, run func(ctx context.Context, t *ScheduledTask) error) *Scheduler {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	if opts.QPS <= 0 {
+		opts.QPS = 1
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = time.Minute
+	}
+	if opts.Clock == nil {
+		opts.Clock = realClock{}
+	}
+	return &Scheduler{
+		opts:  opts,
+		run:   run,
+		tasks: map[string]*ScheduledTask{},
+		hosts: map[string]time.Time{},
+		wake:  make(chan struct{}),
+	}
+}
+
+// Schedule adds the task or updates its due time and weight.
+func (s *Scheduler) Schedule(t ScheduledTask) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := t.key()
+	old := s.tasks[key]
+	switch {
+	case old == nil:
+		t.index = -1
+		t.next = nil
+		t.Running = false
+		s.tasks[key] = &t
+		heap.Push(&s.queue, &t)
+	case old.Running:
+		old.next = &t
+		return
+	default:
+		old.Host = t.Host
+		old.Due = t.Due
+		old.Weight = t.Weight
+		heap.Fix(&s.queue, old.index)
+	}
+	s.notify()
+}
+
+// Queue returns a copy of the tasks, running ones first then in the order they
+// will be run.
+func (s *Scheduler) Queue() []ScheduledTask {
+	s.mu.Lock()
+	out := make([]ScheduledTask, 0, len(s.tasks))
+	for _, t := range s.tasks {
+		c := *t
+		c.next = nil
+		out = append(out, c)
+	}
+	s.mu.Unlock()
+	slices.SortFunc(out, func(a, b ScheduledTask) int {
+		if a.Running != b.Running {
+			if a.Running {
+				return -1
+			}
+			return 1
+		}
+		if c := a.Due.Compare(b.Due); c != 0 {
+			return c
+		}
+		if a.Weight != b.Weight {
+			if a.Weight > b.Weight {
+				return -1
+			}
+			return 1
+		}
+		return 0
+	})
+	return out
+}
+
+// Run runs the tasks until the context is canceled.
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for range s.opts.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.worker(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *Scheduler) worker(ctx context.Context) {
+	for ctx.Err() == nil {
+		t, wait, wake := s.next()
+		if t == nil {
+			var timer <-chan time.Time
+			if wait > 0 {
+				timer = s.opts.Clock.After(wait)
+			}
+			select {
+			case <-ctx.Done():
+			case <-wake:
+			case <-timer:
+			}
+			continue
+		}
+		err := s.run(ctx, t)
+		s.finish(t, err)
+	}
+}
+
+// next pops the next due task whose host has budget left. If none is ready,
+// it returns how long to wait; zero means until the queue changes.
+func (s *Scheduler) next() (*ScheduledTask, time.Duration, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.opts.Clock.Now()
+	wait := time.Duration(0)
+	setWait := func(at time.Time) {
+		if d := at.Sub(now); wait == 0 || d < wait {
+			wait = d
+		}
+	}
+	var t *ScheduledTask
+	var skipped []*ScheduledTask
+	for len(s.queue) != 0 {
+		head := s.queue[0]
+		if head.Due.After(now) {
+			setWait(head.Due)
+			break
+		}
+		heap.Pop(&s.queue)
+		if h := s.hosts[head.Host]; h.After(now) {
+			// Out of budget for this host, look at the next task.
+			setWait(h)
+			skipped = append(skipped, head)
+			continue
+		}
+		t = head
+		break
+	}
+	for _, o := range skipped {
+		heap.Push(&s.queue, o)
+	}
+	if t == nil {
+		return nil, wait, s.wake
+	}
+	t.Running = true
+	t.LastRun = now
+	s.hosts[t.Host] = now.Add(time.Duration(float64(time.Second) / s.opts.QPS))
+	// Return a copy so the callback doesn't race with Schedule.
+	c := *t
+	return &c, 0, s.wake
+}
+
+// finish requeues the task if it failed or was rescheduled while running.
+func (s *Scheduler) finish(c *ScheduledTask, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := c.key()
+	t := s.tasks[key]
+	t.Running = false
+	if err != nil {
+		t.LastErr = err.Error()
+		t.Failures++
+		t.Due = s.opts.Clock.Now().Add(s.opts.Backoff << min(t.Failures-1, 5))
+		if t.next != nil && t.next.Due.Before(t.Due) {
+			t.Due = t.next.Due
+		}
+	} else {
+		t.LastErr = ""
+		t.Failures = 0
+		if t.next == nil {
+			delete(s.tasks, key)
+			return
+		}
+		t.Due = t.next.Due
+	}
+	if t.next != nil {
+		t.Host = t.next.Host
+		t.Weight = t.next.Weight
+		t.next = nil
+	}
+	heap.Push(&s.queue, t)
+	s.notify()
+}
+
+// notify wakes up the idle workers. s.mu must be held.
+func (s *Scheduler) notify() {
+	close(s.wake)
+	s.wake = make(chan struct{})
+}
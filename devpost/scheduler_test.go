@@ -0,0 +1,216 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devpost
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock that only moves forward when advanced.
+type fakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []fakeWaiter
+}
+
+type fakeWaiter struct {
+	at time.Time
+	c  chan time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeClock) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	c := make(chan time.Time, 1)
+	f.waiters = append(f.waiters, fakeWaiter{at: f.now.Add(d), c: c})
+	return c
+}
+
+// advance waits for a worker to be sleeping then moves the time forward.
+func (f *fakeClock) advance(t *testing.T, d time.Duration) {
+	for start := time.Now(); ; time.Sleep(time.Millisecond) {
+		f.mu.Lock()
+		if len(f.waiters) != 0 {
+			break
+		}
+		f.mu.Unlock()
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("no sleeping worker")
+		}
+	}
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+	waiters := f.waiters[:0]
+	for _, w := range f.waiters {
+		if w.at.After(f.now) {
+			waiters = append(waiters, w)
+		} else {
+			w.c <- f.now
+		}
+	}
+	f.waiters = waiters
+}
+
+type schedulerTest struct {
+	t     *testing.T
+	clock *fakeClock
+	s     *Scheduler
+	ran   chan string
+	// fail is the number of times a task fails before succeeding.
+	fail map[string]int
+	// during is called while the task runs.
+	during func(t *ScheduledTask)
+}
+
+func newSchedulerTest(t *testing.T, opts SchedulerOptions) *schedulerTest {
+	st := &schedulerTest{
+		t:     t,
+		clock: &fakeClock{now: time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)},
+		ran:   make(chan string, 100),
+		fail:  map[string]int{},
+	}
+	opts.Clock = st.clock
+	var mu sync.Mutex
+	st.s = NewScheduler(opts, func(ctx context.Context, t *ScheduledTask) error {
+		if st.during != nil {
+			st.during(t)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		st.ran <- t.EventID
+		if st.fail[t.EventID] > 0 {
+			st.fail[t.EventID]--
+			return errors.New("boom")
+		}
+		return nil
+	})
+	ctx, cancel := context.WithCancel(t.Context())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		st.s.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+	return st
+}
+
+func (st *schedulerTest) add(eventID, host string, due time.Duration, weight float64) {
+	st.s.Schedule(ScheduledTask{Kind: TaskProjects, EventID: eventID, Host: host, Due: st.clock.Now().Add(due), Weight: weight})
+}
+
+func (st *schedulerTest) expect(want ...string) {
+	st.t.Helper()
+	for _, w := range want {
+		select {
+		case got := <-st.ran:
+			if got != w {
+				st.t.Fatalf("ran %q, want %q", got, w)
+			}
+		case <-time.After(5 * time.Second):
+			st.t.Fatalf("timed out waiting for %q", w)
+		}
+	}
+	select {
+	case got := <-st.ran:
+		st.t.Fatalf("unexpected run %q", got)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestSchedulerOrder(t *testing.T) {
+	st := newSchedulerTest(t, SchedulerOptions{})
+	st.add("a", "h", 2*time.Second, 0)
+	st.add("b", "h", time.Second, 0)
+	st.add("c", "h", -time.Second, 0)
+	st.expect("c")
+	st.clock.advance(t, time.Second)
+	st.expect("b")
+	st.clock.advance(t, time.Second)
+	st.expect("a")
+	if q := st.s.Queue(); len(q) != 0 {
+		t.Errorf("Expected empty queue, got %+v", q)
+	}
+}
+
+func TestSchedulerHostBudget(t *testing.T) {
+	st := newSchedulerTest(t, SchedulerOptions{QPS: 0.5})
+	st.add("a1", "a", -3*time.Second, 0)
+	st.add("a2", "a", -2*time.Second, 0)
+	// Runs before a2 since host a is out of budget.
+	st.add("b", "b", -time.Second, 0)
+	st.expect("a1", "b")
+	st.clock.advance(t, time.Second)
+	st.expect()
+	st.clock.advance(t, time.Second)
+	st.expect("a2")
+}
+
+func TestSchedulerQueue(t *testing.T) {
+	st := newSchedulerTest(t, SchedulerOptions{})
+	st.add("low", "h", time.Hour, 1)
+	st.add("high", "h", time.Hour, 3)
+	st.add("soon", "h", time.Minute, 0)
+	// Updating an existing task.
+	st.add("low", "h", time.Hour, 2)
+	q := st.s.Queue()
+	if len(q) != 3 || q[0].EventID != "soon" || q[1].EventID != "high" || q[2].EventID != "low" || q[2].Weight != 2 {
+		t.Errorf("Unexpected queue %+v", q)
+	}
+}
+
+func TestSchedulerRetry(t *testing.T) {
+	st := newSchedulerTest(t, SchedulerOptions{Backoff: time.Minute})
+	st.fail["e"] = 2
+	st.add("e", "h", 0, 0)
+	st.expect("e")
+	if q := st.s.Queue(); len(q) != 1 || q[0].Failures != 1 || q[0].LastErr != "boom" || !q[0].Due.Equal(st.clock.Now().Add(time.Minute)) {
+		t.Errorf("Unexpected queue %+v", q)
+	}
+	st.clock.advance(t, time.Minute)
+	st.expect("e")
+	// Doubled.
+	st.clock.advance(t, time.Minute)
+	st.expect()
+	st.clock.advance(t, time.Minute)
+	st.expect("e")
+	if q := st.s.Queue(); len(q) != 0 {
+		t.Errorf("Expected empty queue, got %+v", q)
+	}
+}
+
+func TestSchedulerRescheduleWhileRunning(t *testing.T) {
+	st := newSchedulerTest(t, SchedulerOptions{})
+	st.during = func(t *ScheduledTask) {
+		if t.Weight == 0 {
+			st.add(t.EventID, t.Host, time.Hour, 1)
+		}
+	}
+	st.add("e", "h", 0, 0)
+	st.expect("e")
+	// Wait for the task to be requeued.
+	var q []ScheduledTask
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(time.Millisecond) {
+		if q = st.s.Queue(); len(q) == 1 && !q[0].Running {
+			break
+		}
+	}
+	if len(q) != 1 || q[0].Weight != 1 || !q[0].Due.Equal(st.clock.Now().Add(time.Hour)) {
+		t.Errorf("Unexpected queue %+v", q)
+	}
+}
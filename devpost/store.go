@@ -0,0 +1,580 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devpost
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned by Store when a key doesn't exist.
+var ErrNotFound = errors.New("not found")
+
+// Roast is a LLM generated roast of a project.
+type Roast struct {
+	Content     string    `json:"content"`
+	LastRefresh time.Time `json:"last_refresh"`
+	Hash        string    `json:"hash"`
+}
+
+// Store persists the cached events and roasts.
+//
+// The values are copied on Put and Get, so the caller keeps ownership.
+type Store interface {
+	io.Closer
+	GetEvent(eventID string) (*Event, error)
+	PutEvent(e *Event) error
+	GetProject(eventID, projectID string) (*Project, error)
+	// PutProject updates one project of an event that was previously stored.
+	PutProject(eventID string, p *Project) error
+	GetRoast(projectID string) (*Roast, error)
+	PutRoast(projectID string, r *Roast) error
+	ListEvents() ([]string, error)
+	ListRoasts() ([]string, error)
+	// Snapshot writes a consistent JSON dump of the whole store.
+	Snapshot(w io.Writer) error
+	// Flush persists the pending writes to disk.
+	Flush() error
+}
+
+// serializedCache is the on-disk format of the file based stores.
+type serializedCache struct {
+	Version int                        `json:"version"`
+	Events  map[string]json.RawMessage `json:"events,omitempty"`
+	Roasts  map[string]json.RawMessage `json:"roasts,omitempty"`
+}
+
+const (
+	eventPrefix = "event/"
+	roastPrefix = "roast/"
+)
+
+// memStore is the in-memory index shared by the Store implementations. Values
+// are kept serialized so they are deep copies.
+type memStore struct {
+	mu   sync.Mutex
+	data map[string]json.RawMessage
+	// put is called with mu held for every write.
+	put func(key string, value json.RawMessage) error
+}
+
+func (m *memStore) get(key string, v any) error {
+	m.mu.Lock()
+	b, ok := m.data[key]
+	m.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%s: %w", key, ErrNotFound)
+	}
+	return json.Unmarshal(b, v)
+}
+
+func (m *memStore) set(key string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key] = b
+	return m.put(key, b)
+}
+
+func (m *memStore) list(prefix string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []string
+	for k := range m.data {
+		if strings.HasPrefix(k, prefix) {
+			out = append(out, k[len(prefix):])
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+func (m *memStore) GetEvent(eventID string) (*Event, error) {
+	e := &Event{}
+	if err := m.get(eventPrefix+eventID, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (m *memStore) PutEvent(e *Event) error {
+	return m.set(eventPrefix+e.ID, e)
+}
+
+func (m *memStore) GetProject(eventID, projectID string) (*Project, error) {
+	e, err := m.GetEvent(eventID)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range e.Projects {
+		if p.ID == projectID {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("%s%s/%s: %w", eventPrefix, eventID, projectID, ErrNotFound)
+}
+
+func (m *memStore) PutProject(eventID string, p *Project) error {
+	// Hold the lock across the read-modify-write.
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := eventPrefix + eventID
+	b, ok := m.data[key]
+	if !ok {
+		return fmt.Errorf("%s: %w", key, ErrNotFound)
+	}
+	e := &Event{}
+	if err := json.Unmarshal(b, e); err != nil {
+		return err
+	}
+	i := slices.IndexFunc(e.Projects, func(o *Project) bool { return o.ID == p.ID })
+	if i == -1 {
+		e.Projects = append(e.Projects, p)
+	} else {
+		e.Projects[i] = p
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	m.data[key] = b
+	return m.put(key, b)
+}
+
+func (m *memStore) GetRoast(projectID string) (*Roast, error) {
+	r := &Roast{}
+	if err := m.get(roastPrefix+projectID, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (m *memStore) PutRoast(projectID string, r *Roast) error {
+	return m.set(roastPrefix+projectID, r)
+}
+
+func (m *memStore) ListEvents() ([]string, error) {
+	return m.list(eventPrefix), nil
+}
+
+func (m *memStore) ListRoasts() ([]string, error) {
+	return m.list(roastPrefix), nil
+}
+
+func (m *memStore) Snapshot(w io.Writer) error {
+	m.mu.Lock()
+	data := m.serialize()
+	m.mu.Unlock()
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(data)
+}
+
+// serialize returns the on-disk representation. m.mu must be held.
+func (m *memStore) serialize() *serializedCache {
+	data := &serializedCache{
+		Version: storeVersion,
+		Events:  map[string]json.RawMessage{},
+		Roasts:  map[string]json.RawMessage{},
+	}
+	for k, v := range m.data {
+		if id, ok := strings.CutPrefix(k, eventPrefix); ok {
+			data.Events[id] = v
+		} else if id, ok := strings.CutPrefix(k, roastPrefix); ok {
+			data.Roasts[id] = v
+		}
+	}
+	return data
+}
+
+// load imports the on-disk representation. m.mu must be held.
+func (m *memStore) load(data *serializedCache) {
+	for id, v := range data.Events {
+		m.data[eventPrefix+id] = v
+	}
+	for id, v := range data.Roasts {
+		m.data[roastPrefix+id] = v
+	}
+}
+
+// flusher calls flush every interval until done is closed.
+func flusher(interval time.Duration, done <-chan struct{}, wg *sync.WaitGroup, flush func() error) {
+	defer wg.Done()
+	if interval <= 0 {
+		return
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-t.C:
+			if err := flush(); err != nil {
+				slog.Error("devpost", "msg", "failed to flush store", "err", err)
+			}
+		}
+	}
+}
+
+// writeFileAtomic writes the file via a temporary file and a rename, so a crash
+// never leaves a partially written file behind.
+func writeFileAtomic(path string, write func(w io.Writer) error) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+	w := bufio.NewWriter(f)
+	err = write(w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// fileStore keeps everything in memory and periodically rewrites a single JSON
+// file atomically.
+type fileStore struct {
+	memStore
+	path  string
+	dirty bool
+
+	flushMu sync.Mutex
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewFileStore returns a Store persisted as a single JSON file.
+//
+// Pending writes are flushed every flushInterval and on Close. The file is
+// replaced atomically so a crash never corrupts it.
+func NewFileStore(path string, flushInterval time.Duration) (Store, error) {
+	s := &fileStore{
+		memStore: memStore{data: map[string]json.RawMessage{}},
+		path:     path,
+		done:     make(chan struct{}),
+	}
+	s.put = func(string, json.RawMessage) error {
+		s.dirty = true
+		return nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if len(b) != 0 {
+		data := serializedCache{}
+		if err := json.Unmarshal(b, &data); err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", path, err)
+		}
+		from := data.Version
+		migrated, err := migrate(&data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", path, err)
+		}
+		s.load(&data)
+		if migrated {
+			if err := backupFile(path, from); err != nil {
+				return nil, err
+			}
+			s.dirty = true
+			if err := s.Flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	slog.Info("devpost", "msg", "loaded store", "path", path, "keys", len(s.data))
+	s.wg.Add(1)
+	go flusher(flushInterval, s.done, &s.wg, s.Flush)
+	return s, nil
+}
+
+func (s *fileStore) Flush() error {
+	// Serialize concurrent flushes, so an older snapshot never overwrites a
+	// newer one.
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+	s.mu.Lock()
+	if !s.dirty {
+		s.mu.Unlock()
+		return nil
+	}
+	data := s.serialize()
+	s.dirty = false
+	s.mu.Unlock()
+	err := writeFileAtomic(s.path, func(w io.Writer) error {
+		e := json.NewEncoder(w)
+		e.SetIndent("", "  ")
+		return e.Encode(data)
+	})
+	if err != nil {
+		s.mu.Lock()
+		s.dirty = true
+		s.mu.Unlock()
+	}
+	slog.Info("devpost", "msg", "saved store", "path", s.path, "err", err)
+	return err
+}
+
+func (s *fileStore) Close() error {
+	close(s.done)
+	s.wg.Wait()
+	return s.Flush()
+}
+
+// logStore is an embedded key-value store persisted as an append-only log of
+// JSON lines. The log is compacted when opened and on Flush once most of it is
+// overwritten records.
+//
+// The first record holds the storeVersion under versionKey.
+type logStore struct {
+	memStore
+	path string
+	f    *os.File
+	w    *bufio.Writer
+	// size is the size of the log in bytes.
+	size int64
+	// compactSize is the log size under which it is never compacted while
+	// running.
+	compactSize int64
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// versionKey is the key of the logStore record holding the format version.
+const versionKey = "version"
+
+// logRecord is one line of the logStore.
+type logRecord struct {
+	Key   string          `json:"k"`
+	Value json.RawMessage `json:"v"`
+}
+
+// NewLogStore returns a Store persisted as an append-only log.
+//
+// Writes are appended to the log and synced to disk every flushInterval and
+// on Close. The log is rewritten when it grows past 1MiB and more than half of
+// it is overwritten records. A partially written last record, e.g. on crash,
+// is ignored.
+func NewLogStore(path string, flushInterval time.Duration) (Store, error) {
+	s := &logStore{
+		memStore:    memStore{data: map[string]json.RawMessage{}},
+		path:        path,
+		compactSize: 1 << 20,
+		done:        make(chan struct{}),
+	}
+	s.put = s.append
+	records, version, err := s.replay()
+	if err != nil {
+		return nil, err
+	}
+	migrated := false
+	if records != 0 {
+		if version == 0 {
+			// Logs written before the version record was added.
+			version = 1
+		}
+		data := s.serialize()
+		data.Version = version
+		if migrated, err = migrate(data); err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", path, err)
+		}
+		if migrated {
+			if err := backupFile(path, version); err != nil {
+				return nil, err
+			}
+			clear(s.data)
+			s.load(data)
+		}
+	}
+	if migrated || records != len(s.data)+1 {
+		err = s.compact()
+	} else {
+		err = s.open()
+	}
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("devpost", "msg", "loaded store", "path", path, "keys", len(s.data), "records", records)
+	s.wg.Add(1)
+	go flusher(flushInterval, s.done, &s.wg, s.Flush)
+	return s, nil
+}
+
+// replay loads the log in memory and returns the number of records read and
+// the format version.
+func (s *logStore) replay() (int, int, error) {
+	f, err := os.Open(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, storeVersion, nil
+		}
+		return 0, 0, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	records := 0
+	version := 0
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			if len(bytes.TrimSpace(line)) != 0 {
+				slog.Warn("devpost", "msg", "ignoring truncated record", "path", s.path)
+				// Force a compaction to get rid of it.
+				records++
+			}
+			return records, version, nil
+		}
+		if err != nil {
+			return records, version, err
+		}
+		records++
+		rec := logRecord{}
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return records, version, fmt.Errorf("failed to load %s record %d: %w", s.path, records, err)
+		}
+		if rec.Key == versionKey {
+			if err := json.Unmarshal(rec.Value, &version); err != nil {
+				return records, version, fmt.Errorf("failed to load %s version: %w", s.path, err)
+			}
+		} else if rec.Value == nil {
+			delete(s.data, rec.Key)
+		} else {
+			s.data[rec.Key] = rec.Value
+		}
+	}
+}
+
+// open opens the log for appending.
+func (s *logStore) open() error {
+	var err error
+	if s.f, err = os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644); err != nil {
+		return err
+	}
+	fi, err := s.f.Stat()
+	if err != nil {
+		return err
+	}
+	s.size = fi.Size()
+	if s.w == nil {
+		s.w = bufio.NewWriter(s.f)
+	} else {
+		s.w.Reset(s.f)
+	}
+	return nil
+}
+
+// compact rewrites the log so it only contains the version and the latest
+// value of each key, then reopens it. The writes must have been flushed.
+func (s *logStore) compact() error {
+	if s.f != nil {
+		if err := s.f.Close(); err != nil {
+			return err
+		}
+	}
+	err := writeFileAtomic(s.path, func(w io.Writer) error {
+		v, _ := json.Marshal(storeVersion)
+		if _, err := writeRecord(w, versionKey, v); err != nil {
+			return err
+		}
+		for _, k := range slices.Sorted(maps.Keys(s.data)) {
+			if _, err := writeRecord(w, k, s.data[k]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	// Reopen the log even on failure so the writes can continue.
+	if err2 := s.open(); err == nil {
+		err = err2
+	}
+	return err
+}
+
+// garbage returns true if most of the log is made of overwritten records.
+func (s *logStore) garbage() bool {
+	if s.size < s.compactSize {
+		return false
+	}
+	// This is an estimate of the compacted size, it ignores the JSON escaping
+	// of the keys.
+	live := int64(0)
+	for k, v := range s.data {
+		live += int64(len(`{"k":"","v":}`)+len(k)+len(v)) + 1
+	}
+	return s.size > 2*live
+}
+
+// writeRecord writes one line and returns its size.
+func writeRecord(w io.Writer, key string, value json.RawMessage) (int, error) {
+	b, err := json.Marshal(logRecord{Key: key, Value: value})
+	if err != nil {
+		return 0, err
+	}
+	return w.Write(append(b, '\n'))
+}
+
+// append writes the record to the log. s.mu must be held.
+func (s *logStore) append(key string, value json.RawMessage) error {
+	n, err := writeRecord(s.w, key, value)
+	s.size += int64(n)
+	return err
+}
+
+func (s *logStore) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.w.Buffered() == 0 {
+		return nil
+	}
+	if err := s.w.Flush(); err != nil {
+		return err
+	}
+	if err := s.f.Sync(); err != nil {
+		return err
+	}
+	if s.garbage() {
+		slog.Info("devpost", "msg", "compacting store", "path", s.path, "size", s.size)
+		return s.compact()
+	}
+	return nil
+}
+
+func (s *logStore) Close() error {
+	close(s.done)
+	s.wg.Wait()
+	err := s.Flush()
+	if err2 := s.f.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
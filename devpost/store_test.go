@@ -0,0 +1,186 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devpost
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestStores(t *testing.T) {
+	for name, open := range map[string]func(string, time.Duration) (Store, error){
+		"file": NewFileStore,
+		"log":  NewLogStore,
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "store")
+			s, err := open(path, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.GetEvent("e"); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Expected ErrNotFound, got %v", err)
+			}
+			e := &Event{ID: "e", Projects: []*Project{{ID: "1", Title: "One"}}}
+			if err := s.PutEvent(e); err != nil {
+				t.Fatal(err)
+			}
+			// The store keeps a copy.
+			e.Projects[0].Title = "Mutated"
+			if err := s.PutProject("e", &Project{ID: "2", Title: "Two"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.PutProject("e", &Project{ID: "1", Title: "Uno"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.PutRoast("1", &Roast{Content: "lol"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			// Reopen and verify everything was persisted.
+			if s, err = open(path, 0); err != nil {
+				t.Fatal(err)
+			}
+			defer s.Close()
+			if ids, _ := s.ListEvents(); !slices.Equal(ids, []string{"e"}) {
+				t.Errorf("ListEvents() = %q", ids)
+			}
+			if ids, _ := s.ListRoasts(); !slices.Equal(ids, []string{"1"}) {
+				t.Errorf("ListRoasts() = %q", ids)
+			}
+			p, err := s.GetProject("e", "1")
+			if err != nil || p.Title != "Uno" {
+				t.Errorf("GetProject() = %v, %v", p, err)
+			}
+			got, err := s.GetEvent("e")
+			if err != nil || len(got.Projects) != 2 {
+				t.Errorf("GetEvent() = %v, %v", got, err)
+			}
+			if r, err := s.GetRoast("1"); err != nil || r.Content != "lol" {
+				t.Errorf("GetRoast() = %v, %v", r, err)
+			}
+			buf := bytes.Buffer{}
+			if err := s.Snapshot(&buf); err != nil {
+				t.Fatal(err)
+			}
+			data := serializedCache{}
+			if err := json.Unmarshal(buf.Bytes(), &data); err != nil {
+				t.Fatal(err)
+			}
+			if len(data.Events) != 1 || len(data.Roasts) != 1 {
+				t.Errorf("Unexpected snapshot: %s", buf.String())
+			}
+			// No temporary file is left behind.
+			if m, _ := filepath.Glob(path + ".*"); len(m) != 0 {
+				t.Errorf("Unexpected files: %q", m)
+			}
+		})
+	}
+}
+
+func TestLogStoreTruncated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.log")
+	s, err := NewLogStore(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PutRoast("1", &Roast{Content: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PutRoast("1", &Roast{Content: "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// Simulate a crash in the middle of a write.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"k":"roast/2","v":{"cont`); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if s, err = NewLogStore(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if r, err := s.GetRoast("1"); err != nil || r.Content != "b" {
+		t.Errorf("GetRoast() = %v, %v", r, err)
+	}
+	if ids, _ := s.ListRoasts(); len(ids) != 1 {
+		t.Errorf("ListRoasts() = %q", ids)
+	}
+	// The log was compacted to the version and the roast.
+	if b, _ := os.ReadFile(path); bytes.Count(b, []byte("\n")) != 2 {
+		t.Errorf("Expected a compacted log, got %q", b)
+	}
+}
+
+func TestLogStoreCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.log")
+	s, err := NewLogStore(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.(*logStore).compactSize = 1024
+	lines := func() int {
+		b, _ := os.ReadFile(path)
+		return bytes.Count(b, []byte("\n"))
+	}
+	// Too small to be worth compacting.
+	for i := range 3 {
+		if err := s.PutRoast("1", &Roast{Content: strconv.Itoa(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if n := lines(); n != 4 {
+		t.Errorf("Expected 4 records, got %d", n)
+	}
+	for i := range 100 {
+		if err := s.PutRoast("1", &Roast{Content: strconv.Itoa(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if n := lines(); n != 2 {
+		t.Errorf("Expected a compacted log, got %d records", n)
+	}
+	// The writes continue in the new log.
+	if err := s.PutRoast("2", &Roast{Content: "b"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if s, err = NewLogStore(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if r, err := s.GetRoast("1"); err != nil || r.Content != "99" {
+		t.Errorf("GetRoast() = %v, %v", r, err)
+	}
+	if r, err := s.GetRoast("2"); err != nil || r.Content != "b" {
+		t.Errorf("GetRoast() = %v, %v", r, err)
+	}
+}
@@ -0,0 +1,289 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devpost
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxDeliveries bounds the webhook delivery log.
+const maxDeliveries = 500
+
+// WebhookFormat is the payload format sent to a webhook.
+type WebhookFormat string
+
+const (
+	// WebhookJSON sends the Change as-is.
+	WebhookJSON WebhookFormat = "json"
+	// WebhookSlack sends a Slack incoming webhook message with blocks.
+	WebhookSlack WebhookFormat = "slack"
+	// WebhookDiscord sends a Discord webhook message with an embed.
+	WebhookDiscord WebhookFormat = "discord"
+)
+
+// Webhook is an outgoing webhook notified of the changes of an event.
+type Webhook struct {
+	EventID string `json:"event_id"`
+	URL     string `json:"url"`
+	// Types filters the changes to send. All the changes except ChangeLikes
+	// are sent when empty.
+	Types  []ChangeType  `json:"types"`
+	Format WebhookFormat `json:"format"`
+	// LikesThresholds are the likes counts that trigger a ChangeLikes
+	// notification when crossed. ChangeLikes is never sent when empty.
+	LikesThresholds []int `json:"likes_thresholds"`
+	// Secret, when set, is used to sign the body with HMAC-SHA256. The
+	// signature is sent in the X-Devpostdash-Signature header as
+	// "sha256=<hex>".
+	Secret string `json:"secret"`
+}
+
+// wants returns true if the change should be sent to this webhook.
+func (w *Webhook) wants(c *Change) bool {
+	if c.Type == ChangeLikes {
+		prev := c.Likes - c.LikesDelta
+		crossed := slices.ContainsFunc(w.LikesThresholds, func(t int) bool { return prev < t && t <= c.Likes })
+		return crossed && (len(w.Types) == 0 || slices.Contains(w.Types, ChangeLikes))
+	}
+	return len(w.Types) == 0 || slices.Contains(w.Types, c.Type)
+}
+
+// payload returns the body to send for the change.
+func (w *Webhook) payload(c *Change) ([]byte, error) {
+	text := summarize(c)
+	switch w.Format {
+	case WebhookSlack:
+		return json.Marshal(map[string]any{
+			"text": text,
+			"blocks": []any{
+				map[string]any{
+					"type": "section",
+					"text": map[string]string{"type": "mrkdwn", "text": slackText(c, text)},
+				},
+			},
+		})
+	case WebhookDiscord:
+		return json.Marshal(map[string]any{
+			"embeds": []any{
+				map[string]any{
+					"title":       c.Title,
+					"description": text,
+					"url":         c.URL,
+					"timestamp":   c.Time.Format(time.RFC3339),
+				},
+			},
+		})
+	case WebhookJSON, "":
+		return json.Marshal(c)
+	default:
+		return nil, fmt.Errorf("unknown webhook format %q", w.Format)
+	}
+}
+
+// summarize returns a one line human readable description of the change.
+func summarize(c *Change) string {
+	switch c.Type {
+	case ChangeAdded:
+		return fmt.Sprintf("New submission: %s", c.Title)
+	case ChangeRemoved:
+		return fmt.Sprintf("%s was removed", c.Title)
+	case ChangeUpdated:
+		return fmt.Sprintf("%s updated %s", c.Title, strings.Join(c.Fields, ", "))
+	case ChangeWinner:
+		return fmt.Sprintf("%s is a winner! 🏆", c.Title)
+	case ChangeLikes:
+		return fmt.Sprintf("%s reached %d likes ❤️", c.Title, c.Likes)
+	default:
+		return fmt.Sprintf("%s: %s", c.Title, c.Type)
+	}
+}
+
+func slackText(c *Change, text string) string {
+	if c.URL == "" {
+		return text
+	}
+	return fmt.Sprintf("%s\n<%s|%s>", text, c.URL, c.Title)
+}
+
+// Delivery is one entry in the webhook delivery log.
+type Delivery struct {
+	Time       time.Time  `json:"time"`
+	EventID    string     `json:"event_id"`
+	URL        string     `json:"url"`
+	Seq        int64      `json:"seq"`
+	Type       ChangeType `json:"type"`
+	Attempts   int        `json:"attempts"`
+	StatusCode int        `json:"status_code"`
+	Err        string     `json:"err,omitempty"`
+}
+
+// Webhooks delivers the changes detected by the cached client to outgoing
+// webhooks.
+type Webhooks struct {
+	c     http.Client
+	hooks []Webhook
+
+	// backoff is the initial delay between attempts, doubled after each
+	// failure.
+	backoff     time.Duration
+	maxAttempts int
+
+	mu         sync.Mutex
+	deliveries []Delivery
+}
+
+// NewWebhooks returns a webhook dispatcher. Call Run to start it.
+func NewWebhooks(h http.RoundTripper, hooks []Webhook) (*Webhooks, error) {
+	for _, w := range hooks {
+		if w.EventID == "" || w.URL == "" {
+			return nil, errors.New("webhook requires event_id and url")
+		}
+		if _, err := w.payload(&Change{}); err != nil {
+			return nil, err
+		}
+	}
+	return &Webhooks{
+		c:           http.Client{Transport: h, Timeout: 30 * time.Second},
+		hooks:       hooks,
+		backoff:     time.Second,
+		maxAttempts: 5,
+	}, nil
+}
+
+// Run delivers the changes of the events with a webhook until the context is
+// canceled.
+//
+// The events are kept requested so the cached client keeps refreshing them.
+func (w *Webhooks) Run(ctx context.Context, c CachedClient) {
+	var eventIDs []string
+	for _, h := range w.hooks {
+		if !slices.Contains(eventIDs, h.EventID) {
+			eventIDs = append(eventIDs, h.EventID)
+		}
+	}
+	var wg sync.WaitGroup
+	for _, eventID := range eventIDs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.watch(ctx, c, eventID)
+		}()
+	}
+	wg.Wait()
+}
+
+func (w *Webhooks) watch(ctx context.Context, c CachedClient, eventID string) {
+	// Only send the changes that happen from now on.
+	since := int64(0)
+	if changes, err := c.FetchChanges(ctx, eventID, 0); err == nil && len(changes) != 0 {
+		since = changes[len(changes)-1].Seq
+	}
+	for ctx.Err() == nil {
+		if _, err := c.FetchProjects(ctx, eventID); err != nil {
+			slog.WarnContext(ctx, "webhook", "eventID", eventID, "err", err)
+		}
+		waitCtx, cancel := context.WithTimeout(ctx, time.Minute)
+		changes, err := c.WaitChanges(waitCtx, eventID, since)
+		cancel()
+		if err != nil {
+			continue
+		}
+		w.handle(ctx, changes)
+		since = changes[len(changes)-1].Seq
+	}
+}
+
+// handle sends the changes to the matching webhooks.
+func (w *Webhooks) handle(ctx context.Context, changes []Change) {
+	for i := range changes {
+		for j := range w.hooks {
+			if h := &w.hooks[j]; h.EventID == changes[i].EventID && h.wants(&changes[i]) {
+				w.deliver(ctx, h, &changes[i])
+			}
+		}
+	}
+}
+
+// deliver sends the change, retrying with exponential backoff on transient
+// failures.
+func (w *Webhooks) deliver(ctx context.Context, h *Webhook, c *Change) {
+	d := Delivery{Time: time.Now(), EventID: c.EventID, URL: h.URL, Seq: c.Seq, Type: c.Type}
+	body, err := h.payload(c)
+	for delay := w.backoff; err == nil && d.Attempts < w.maxAttempts; delay *= 2 {
+		d.Attempts++
+		var retry bool
+		if d.StatusCode, retry, err = w.post(ctx, h, c, body); err == nil || !retry || d.Attempts == w.maxAttempts {
+			break
+		}
+		// Add up to 25% of jitter.
+		t := time.NewTimer(delay + rand.N(delay/4+1))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			err = ctx.Err()
+		case <-t.C:
+			err = nil
+		}
+	}
+	if err != nil {
+		d.Err = err.Error()
+	}
+	slog.InfoContext(ctx, "webhook", "url", h.URL, "seq", c.Seq, "type", c.Type, "attempts", d.Attempts, "status", d.StatusCode, "err", err)
+	w.mu.Lock()
+	w.deliveries = append(w.deliveries, d)
+	if len(w.deliveries) > maxDeliveries {
+		w.deliveries = append([]Delivery(nil), w.deliveries[len(w.deliveries)-maxDeliveries:]...)
+	}
+	w.mu.Unlock()
+}
+
+// post does one attempt. It returns true if the error is transient.
+func (w *Webhooks) post(ctx context.Context, h *Webhook, c *Change, body []byte) (int, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", h.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Devpostdash-Delivery", strconv.FormatInt(c.Seq, 10))
+	if h.Secret != "" {
+		m := hmac.New(sha256.New, []byte(h.Secret))
+		m.Write(body)
+		req.Header.Set("X-Devpostdash-Signature", "sha256="+hex.EncodeToString(m.Sum(nil)))
+	}
+	resp, err := w.c.Do(req)
+	if err != nil {
+		return 0, ctx.Err() == nil, err
+	}
+	bod, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return resp.StatusCode, retry, &HTTPError{StatusCode: resp.StatusCode, Body: bod}
+	}
+	return resp.StatusCode, false, nil
+}
+
+// Deliveries returns a copy of the delivery log, oldest first.
+func (w *Webhooks) Deliveries() []Delivery {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return slices.Clone(w.deliveries)
+}
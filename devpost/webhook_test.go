@@ -0,0 +1,106 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package devpost
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWebhooksDeliver(t *testing.T) {
+	var mu sync.Mutex
+	var bodies [][]byte
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		m := hmac.New(sha256.New, []byte("s3cret"))
+		m.Write(b)
+		if got, want := r.Header.Get("X-Devpostdash-Signature"), "sha256="+hex.EncodeToString(m.Sum(nil)); got != want {
+			t.Errorf("Signature = %q, want %q", got, want)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		// Fail the first attempt.
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		bodies = append(bodies, b)
+	}))
+	defer ts.Close()
+
+	w, err := NewWebhooks(http.DefaultTransport, []Webhook{
+		{EventID: "e", URL: ts.URL, Format: WebhookSlack, Secret: "s3cret", LikesThresholds: []int{10}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.backoff = time.Millisecond
+	w.handle(t.Context(), []Change{
+		{Seq: 1, EventID: "e", Type: ChangeAdded, Title: "Gopher"},
+		// Does not cross the threshold.
+		{Seq: 2, EventID: "e", Type: ChangeLikes, Title: "Gopher", Likes: 5, LikesDelta: 5},
+		{Seq: 3, EventID: "e", Type: ChangeLikes, Title: "Gopher", Likes: 12, LikesDelta: 7},
+		// Another event.
+		{Seq: 1, EventID: "other", Type: ChangeAdded, Title: "Other"},
+	})
+
+	if len(bodies) != 2 {
+		t.Fatalf("Expected 2 deliveries, got %d", len(bodies))
+	}
+	var msg struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(bodies[0], &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Text != "New submission: Gopher" {
+		t.Errorf("Unexpected text %q", msg.Text)
+	}
+	d := w.Deliveries()
+	if len(d) != 2 || d[0].Attempts != 2 || d[0].StatusCode != 200 || d[0].Err != "" || d[1].Seq != 3 {
+		t.Errorf("Unexpected deliveries: %+v", d)
+	}
+}
+
+func TestWebhooksNoRetryOnClientError(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	w, err := NewWebhooks(http.DefaultTransport, []Webhook{{EventID: "e", URL: ts.URL, Format: WebhookDiscord}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.backoff = time.Millisecond
+	w.handle(t.Context(), []Change{{Seq: 1, EventID: "e", Type: ChangeWinner, Title: "Gopher"}})
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+	if d := w.Deliveries(); len(d) != 1 || d[0].StatusCode != 404 || d[0].Err == "" {
+		t.Errorf("Unexpected deliveries: %+v", d)
+	}
+}
+
+func TestNewWebhooksInvalid(t *testing.T) {
+	if _, err := NewWebhooks(nil, []Webhook{{EventID: "e", URL: "http://localhost", Format: "xml"}}); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+	if _, err := NewWebhooks(nil, []Webhook{{URL: "http://localhost"}}); err == nil {
+		t.Error("Expected error for missing event_id")
+	}
+}
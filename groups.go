@@ -0,0 +1,221 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/maruel/devpostdash/devpost"
+)
+
+// eventGroup is a named set of events shown as one dashboard, e.g. the
+// regional sub-events of a global hackathon.
+type eventGroup struct {
+	// Title defaults to the name of the group.
+	Title  string   `json:"title"`
+	Events []string `json:"events"`
+}
+
+// groupSet is the set of named event groups. It is replaced when the
+// configuration is reloaded. A nil groupSet is empty.
+type groupSet struct {
+	mu     sync.Mutex
+	groups map[string]eventGroup
+}
+
+func newGroupSet(groups map[string]eventGroup) *groupSet {
+	return &groupSet{groups: groups}
+}
+
+func (g *groupSet) get(name string) (eventGroup, bool) {
+	if g == nil {
+		return eventGroup{}, false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	out, ok := g.groups[name]
+	return out, ok
+}
+
+func (g *groupSet) set(groups map[string]eventGroup) {
+	g.mu.Lock()
+	g.groups = groups
+	g.mu.Unlock()
+}
+
+// maxGroupEvents is the maximum number of events shown as one.
+const maxGroupEvents = 20
+
+// loadGroups loads the event groups from a JSON file mapping the group name to
+// the group.
+func loadGroups(path string) (map[string]eventGroup, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var groups map[string]eventGroup
+	if err := json.Unmarshal(b, &groups); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if err := checkGroups(groups); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// checkGroups returns an error if a group has an invalid event.
+func checkGroups(groups map[string]eventGroup) error {
+	for name, g := range groups {
+		if _, err := parseEvents(strings.Join(g.Events, "+")); err != nil {
+			return fmt.Errorf("group %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+var reEventID = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+
+// parseEvents returns the event IDs of an event spec. A spec is one event ID
+// or several joined with "+", e.g. "emea+apac+amer".
+func parseEvents(spec string) ([]string, error) {
+	var ids []string
+	for id := range strings.SplitSeq(spec, "+") {
+		if !reEventID.MatchString(id) {
+			return nil, &devpost.HTTPError{StatusCode: http.StatusBadRequest, Body: fmt.Appendf(nil, "invalid event %q", id)}
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > maxGroupEvents {
+		return nil, &devpost.HTTPError{StatusCode: http.StatusBadRequest, Body: fmt.Appendf(nil, "too many events, at most %d", maxGroupEvents)}
+	}
+	return ids, nil
+}
+
+// cursor is the position in the change logs of a set of events: the sequence
+// number of the last change seen for each event.
+type cursor map[string]int64
+
+// parseCursor parses a cursor as formatted by format. It returns nil if s is
+// empty, negative or doesn't cover all the events, in which case the client
+// must resync.
+func parseCursor(ids []string, s string) (cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if len(ids) == 1 {
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, &devpost.HTTPError{StatusCode: http.StatusBadRequest, Body: []byte(err.Error())}
+		}
+		if v < 0 {
+			return nil, nil
+		}
+		return cursor{ids[0]: v}, nil
+	}
+	c := cursor{}
+	for item := range strings.SplitSeq(s, ",") {
+		id, seq, ok := strings.Cut(item, ":")
+		v, err := strconv.ParseInt(seq, 10, 64)
+		if !ok || err != nil {
+			return nil, &devpost.HTTPError{StatusCode: http.StatusBadRequest, Body: fmt.Appendf(nil, "invalid cursor %q", s)}
+		}
+		c[id] = v
+	}
+	for _, id := range ids {
+		if _, ok := c[id]; !ok {
+			return nil, nil
+		}
+	}
+	return c, nil
+}
+
+// format returns the cursor as a plain sequence number for a single event, to
+// stay compatible with the clients of single events, or as "id:seq,id:seq"
+// for a group.
+func (c cursor) format(ids []string) string {
+	if len(ids) == 1 {
+		return strconv.FormatInt(c[ids[0]], 10)
+	}
+	items := make([]string, len(ids))
+	for i, id := range ids {
+		items[i] = id + ":" + strconv.FormatInt(c[id], 10)
+	}
+	return strings.Join(items, ",")
+}
+
+// waitChanges waits for changes in any of the events after the cursor. It
+// returns the changes of each event that had some.
+func waitChanges(ctx context.Context, d devpost.CachedClient, ids []string, since cursor) (map[string][]devpost.Change, error) {
+	if len(ids) == 1 {
+		changes, err := d.WaitChanges(ctx, ids[0], since[ids[0]])
+		if err != nil {
+			return nil, err
+		}
+		return map[string][]devpost.Change{ids[0]: changes}, nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type result struct {
+		id      string
+		changes []devpost.Change
+		err     error
+	}
+	ch := make(chan result, len(ids))
+	for _, id := range ids {
+		go func() {
+			changes, err := d.WaitChanges(ctx, id, since[id])
+			ch <- result{id, changes, err}
+		}()
+	}
+	// Return on the first result but keep the changes of the events that
+	// changed at the same time.
+	first := <-ch
+	cancel()
+	out := map[string][]devpost.Change{}
+	if first.err == nil {
+		out[first.id] = first.changes
+	}
+	for range len(ids) - 1 {
+		if r := <-ch; r.err == nil && first.err == nil {
+			out[r.id] = r.changes
+		}
+	}
+	if first.err != nil {
+		return nil, first.err
+	}
+	return out, nil
+}
+
+// mergeInfo returns the metadata of a set of events shown as one.
+func mergeInfo(infos []*devpost.EventInfo) *devpost.EventInfo {
+	if len(infos) == 1 {
+		return infos[0]
+	}
+	out := &devpost.EventInfo{}
+	var titles []string
+	for _, info := range infos {
+		titles = append(titles, info.Title)
+		out.Participants += info.Participants
+		out.Challenges = append(out.Challenges, info.Challenges...)
+		if out.Deadline.IsZero() || (!info.Deadline.IsZero() && info.Deadline.After(out.Deadline)) {
+			out.Deadline = info.Deadline
+		}
+	}
+	out.Title = strings.Join(titles, " + ")
+	slices.Sort(out.Challenges)
+	out.Challenges = slices.Compact(out.Challenges)
+	return out
+}
@@ -0,0 +1,308 @@
+// Copyright 2025 Marc-Antoine Ruel. All rights reserved.
+// Use of this source code is governed under the Apache License, Version 2.0
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maruel/devpostdash/devpost"
+)
+
+func TestParseEvents(t *testing.T) {
+	ids, err := parseEvents("emea+apac+emea")
+	if err != nil || strings.Join(ids, ",") != "emea,apac" {
+		t.Fatalf("parseEvents() = %v, %v", ids, err)
+	}
+	var many []string
+	for i := range maxGroupEvents + 1 {
+		many = append(many, fmt.Sprintf("e%d", i))
+	}
+	for _, spec := range []string{"", "a++b", "a+b.evil.com", strings.Join(many, "+")} {
+		if _, err := parseEvents(spec); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+}
+
+func TestCursor(t *testing.T) {
+	ids := []string{"a", "b"}
+	c, err := parseCursor(ids, "b:3,a:12")
+	if err != nil || c["a"] != 12 || c["b"] != 3 {
+		t.Fatalf("parseCursor() = %v, %v", c, err)
+	}
+	if s := c.format(ids); s != "a:12,b:3" {
+		t.Errorf("format() = %q", s)
+	}
+	// A cursor that doesn't cover all the events needs a resync.
+	if c, err := parseCursor(ids, "a:12"); c != nil || err != nil {
+		t.Errorf("parseCursor() = %v, %v", c, err)
+	}
+	if _, err := parseCursor(ids, "a:x"); err == nil {
+		t.Error("expected an error")
+	}
+	// A single event uses plain sequence numbers.
+	c, err = parseCursor(ids[:1], "7")
+	if err != nil || c.format(ids[:1]) != "7" {
+		t.Fatalf("parseCursor() = %v, %v", c, err)
+	}
+	if c, err := parseCursor(ids[:1], "-1"); c != nil || err != nil {
+		t.Errorf("parseCursor() = %v, %v", c, err)
+	}
+}
+
+func TestLoadGroups(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "groups.json")
+	if err := os.WriteFile(p, []byte(`{"global": {"title": "Global", "events": ["emea", "apac"]}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	groups, err := loadGroups(p)
+	if err != nil || groups["global"].Title != "Global" || len(groups["global"].Events) != 2 {
+		t.Fatalf("loadGroups() = %v, %v", groups, err)
+	}
+	if err := os.WriteFile(p, []byte(`{"bad": {"events": ["a/b"]}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadGroups(p); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+// groupClient has a second event "other" with a change log.
+type groupClient struct {
+	mockDevpostClient
+}
+
+var otherProject = &devpost.Project{ID: "9", Title: "Other Project", Likes: 15, Prizes: []string{"Grand Prize"}}
+
+func (g *groupClient) FetchProjects(ctx context.Context, eventID string) ([]*devpost.Project, error) {
+	if eventID == "other" {
+		p := *otherProject
+		return []*devpost.Project{&p}, nil
+	}
+	return g.mockDevpostClient.FetchProjects(ctx, eventID)
+}
+
+func (g *groupClient) FetchPrizes(ctx context.Context, eventID string) ([]devpost.Prize, error) {
+	return []devpost.Prize{{Name: "Grand Prize"}}, nil
+}
+
+func (g *groupClient) FetchChanges(ctx context.Context, eventID string, since int64) ([]devpost.Change, error) {
+	if eventID == "other" && since < 1 {
+		return []devpost.Change{{Seq: 1, Time: time.Unix(1, 0), Type: devpost.ChangeAdded, EventID: "other", ProjectID: "9"}}, nil
+	}
+	return nil, nil
+}
+
+func (g *groupClient) WaitChanges(ctx context.Context, eventID string, since int64) ([]devpost.Change, error) {
+	if c, _ := g.FetchChanges(ctx, eventID, since); len(c) != 0 {
+		return c, nil
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestGroups(t *testing.T) {
+	groups := map[string]eventGroup{"global": {Title: "Global Hack", Events: []string{"fake-event", "other"}}}
+	ts := httptest.NewServer(newWebServerHandler(&groupClient{}, nil, nil, newGroupSet(groups)))
+	defer ts.Close()
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode, string(b)
+	}
+	for _, path := range []string{"/group/global/card", "/group/global/cards", "/group/global/table", "/group/global/3d", "/event/fake-event+other/table"} {
+		code, body := get(path)
+		if code != http.StatusOK {
+			t.Errorf("%s: Expected status OK, got %d", path, code)
+			continue
+		}
+		if path != "/event/fake-event+other/table" && !strings.Contains(body, "Global Hack") {
+			t.Errorf("%s: Response body does not contain the group title", path)
+		}
+	}
+	if code, body := get("/event/fake-event+other/table"); !strings.Contains(body, "Other Project") || !strings.Contains(body, "Fake Event &#43; Fake Event") {
+		t.Errorf("Unexpected response %d: %s", code, body)
+	}
+	if code, _ := get("/group/unknown/card"); code != http.StatusNotFound {
+		t.Errorf("Expected a 404, got %d", code)
+	}
+	if code, _ := get("/api/events/a+b.example.com"); code != http.StatusBadRequest {
+		t.Errorf("Expected a 400, got %d", code)
+	}
+
+	// The projects are merged and sorted by likes across the events.
+	_, body := get("/api/events/fake-event+other")
+	var event eventResponse
+	if err := json.Unmarshal([]byte(body), &event); err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, p := range event.Projects {
+		ids = append(ids, p.EventID+"/"+p.ID)
+	}
+	if got := strings.Join(ids, ","); got != "fake-event/2,other/9,fake-event/1" {
+		t.Errorf("Unexpected projects %s", got)
+	}
+
+	// The prizes are only won by the projects of their event.
+	_, body = get("/api/events/fake-event+other/prizes")
+	var awards []award
+	if err := json.Unmarshal([]byte(body), &awards); err != nil {
+		t.Fatal(err)
+	}
+	if len(awards) != 2 || len(awards[0].Winners) != 0 || strings.Join(awards[1].Winners, ",") != "9" || awards[1].EventID != "other" {
+		t.Errorf("Unexpected awards %+v", awards)
+	}
+
+	_, body = get("/api/events/fake-event+other/changes?since=fake-event:0,other:0")
+	var changes []devpost.Change
+	if err := json.Unmarshal([]byte(body), &changes); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].EventID != "other" {
+		t.Errorf("Unexpected changes %+v", changes)
+	}
+}
+
+func TestGroupStream(t *testing.T) {
+	ts := httptest.NewServer(newWebServerHandler(&groupClient{}, nil, nil, nil))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", ts.URL+"/api/events/fake-event+other/stream", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Last-Event-ID", "fake-event:0,other:0")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to make GET request: %v", err)
+	}
+	defer resp.Body.Close()
+	var lines []string
+	for s := bufio.NewScanner(resp.Body); s.Scan() && s.Text() != ""; {
+		lines = append(lines, s.Text())
+	}
+	if len(lines) != 3 || lines[0] != "id: fake-event:0,other:1" || lines[1] != "event: change" {
+		t.Fatalf("Unexpected event: %q", lines)
+	}
+	if !strings.Contains(lines[2], "Other Project") || !strings.Contains(lines[2], `"event_id":"other"`) {
+		t.Errorf("Unexpected change %s", lines[2])
+	}
+}
+
+// sharedClient has the project "1" of fake-event also submitted to "other".
+type sharedClient struct {
+	groupClient
+}
+
+var sharedProject = &devpost.Project{ID: "1", Title: "Shared Project", Likes: 20}
+
+func (g *sharedClient) FetchProjects(ctx context.Context, eventID string) ([]*devpost.Project, error) {
+	if eventID == "other" {
+		p := *sharedProject
+		return []*devpost.Project{&p}, nil
+	}
+	return g.groupClient.FetchProjects(ctx, eventID)
+}
+
+func (g *sharedClient) FetchChanges(ctx context.Context, eventID string, since int64) ([]devpost.Change, error) {
+	if eventID == "other" && since < 1 {
+		return []devpost.Change{{Seq: 1, Time: time.Unix(1, 0), Type: devpost.ChangeUpdated, EventID: "other", ProjectID: "1", Fields: []string{"likes"}}}, nil
+	}
+	return nil, nil
+}
+
+func (g *sharedClient) WaitChanges(ctx context.Context, eventID string, since int64) ([]devpost.Change, error) {
+	if c, _ := g.FetchChanges(ctx, eventID, since); len(c) != 0 {
+		return c, nil
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestGroupSharedProject(t *testing.T) {
+	ts := httptest.NewServer(newWebServerHandler(&sharedClient{}, nil, nil, nil))
+	defer ts.Close()
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode, string(b)
+	}
+
+	// The project is listed once per event.
+	_, body := get("/api/events/fake-event+other")
+	var event eventResponse
+	if err := json.Unmarshal([]byte(body), &event); err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, p := range event.Projects {
+		ids = append(ids, p.EventID+"/"+p.ID+"/"+p.Title)
+	}
+	if got := strings.Join(ids, ","); got != "fake-event/2/Fake Project Two,other/1/Shared Project,fake-event/1/Fake Project One" {
+		t.Errorf("Unexpected projects %s", got)
+	}
+
+	// The project ID alone is ambiguous.
+	if code, _ := get("/api/events/fake-event+other/projects/1/history"); code != http.StatusBadRequest {
+		t.Errorf("Expected a 400, got %d", code)
+	}
+	if code, _ := get("/api/events/other/projects/1/history"); code != http.StatusOK {
+		t.Errorf("Expected a 200, got %d", code)
+	}
+
+	// The change of "other" carries the project of "other".
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", ts.URL+"/api/events/fake-event+other/stream", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Last-Event-ID", "fake-event:0,other:0")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to make GET request: %v", err)
+	}
+	defer resp.Body.Close()
+	var lines []string
+	for s := bufio.NewScanner(resp.Body); s.Scan() && s.Text() != ""; {
+		lines = append(lines, s.Text())
+	}
+	if len(lines) != 3 || lines[0] != "id: fake-event:0,other:1" || lines[1] != "event: change" {
+		t.Fatalf("Unexpected event: %q", lines)
+	}
+	if !strings.Contains(lines[2], "Shared Project") || strings.Contains(lines[2], "Fake Project One") {
+		t.Errorf("Unexpected change %s", lines[2])
+	}
+}
@@ -7,6 +7,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
@@ -23,8 +24,8 @@ import (
 	"github.com/fsnotify/fsnotify"
 	"github.com/lmittmann/tint"
 	"github.com/maruel/devpostdash/devpost"
+	"github.com/maruel/devpostdash/devpost/devposttest"
 	"github.com/maruel/genai"
-	"github.com/maruel/genai/base"
 	"github.com/maruel/genai/providers"
 	"github.com/maruel/roundtrippers"
 	"github.com/mattn/go-colorable"
@@ -136,6 +137,32 @@ func watchExecutable(ctx context.Context, cancel context.CancelFunc) error {
 	return nil
 }
 
+// openStore opens the store of the specified kind. The file extension is
+// added to base.
+func openStore(kind, base string) (devpost.Store, error) {
+	switch kind {
+	case "file":
+		return devpost.NewFileStore(base+".json", 30*time.Second)
+	case "log":
+		return devpost.NewLogStore(base+".log", 5*time.Second)
+	default:
+		return nil, fmt.Errorf("unknown store %q", kind)
+	}
+}
+
+// loadWebhooks loads the list of webhooks from a JSON file.
+func loadWebhooks(path string) (*devpost.Webhooks, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var hooks []devpost.Webhook
+	if err := json.Unmarshal(b, &hooks); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return devpost.NewWebhooks(http.DefaultTransport, hooks)
+}
+
 func mainImpl() error {
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
 	defer cancel()
@@ -190,12 +217,20 @@ func mainImpl() error {
 		return err
 	}
 
+	defaults := defaultConfig()
 	verbose := flag.Bool("verbose", false, "verbose mode")
 	record := flag.Bool("record", false, "record mode")
-	host := flag.String("host", ":8080", "host")
+	configFile := flag.String("config", "", "JSON or YAML configuration file, reloaded on SIGHUP; see config.go for the schema")
+	host := flag.String("host", defaults.Host, "host")
 	dump := flag.String("dump", "", "dump mode")
-	provider := flag.String("provider", "cerebras", "LLM provider to use")
-	model := flag.String("model", base.PreferredGood, "LLM model to use")
+	provider := flag.String("provider", defaults.LLM.Provider, "LLM provider to use")
+	model := flag.String("model", defaults.LLM.Model, "LLM model to use")
+	webhooks := flag.String("webhooks", "", "JSON file listing the outgoing webhooks")
+	groupsFile := flag.String("groups", "", "JSON file mapping group names to the events shown together at /group/<name>, replacing the groups of the configuration file")
+	storeKind := flag.String("store", defaults.Store, "cache storage: \"file\" (JSON file) or \"log\" (append-only key-value log)")
+	discovery := flag.String("discovery", "gallery,submissions", "comma separated strategies to list the projects, tried in order until one finds projects")
+	galleryConcurrency := flag.Int("gallery-concurrency", 4, "number of project gallery pages fetched concurrently")
+	offline := flag.String("offline", "", "serve devpost.com from the HTML fixtures in this directory instead of the network, e.g. devpost/devposttest/testdata")
 	flag.Parse()
 
 	if flag.NArg() != 0 {
@@ -204,6 +239,31 @@ func mainImpl() error {
 	if *verbose {
 		Level.Set(slog.LevelDebug)
 	}
+	// The flags set explicitly override the configuration file.
+	load := func() (*config, error) {
+		return loadConfig(*configFile, os.LookupEnv, func(cfg *config) error {
+			var err error
+			flag.Visit(func(f *flag.Flag) {
+				switch f.Name {
+				case "host":
+					cfg.Host = *host
+				case "store":
+					cfg.Store = *storeKind
+				case "provider":
+					cfg.LLM.Provider = *provider
+				case "model":
+					cfg.LLM.Model = *model
+				case "groups":
+					cfg.Groups, err = loadGroups(*groupsFile)
+				}
+			})
+			return err
+		})
+	}
+	cfg, err := load()
+	if err != nil {
+		return err
+	}
 
 	h := http.DefaultTransport
 	if *record {
@@ -214,25 +274,62 @@ func mainImpl() error {
 		defer rr.Stop()
 		h = rr
 	}
-	u, err := user.Current()
-	if err != nil {
-		return err
+	cacheName := "devpost"
+	if *offline != "" {
+		s, err := devposttest.NewServer(os.DirFS(*offline), devposttest.Options{Latency: 200 * time.Millisecond})
+		if err != nil {
+			return err
+		}
+		defer s.Close()
+		// Make the fake hackathon progress.
+		go s.Simulate(ctx, 5*time.Second)
+		h = s.Transport()
+		// Don't mix the fake events with the real ones.
+		cacheName = "devpost-offline"
+	}
+	cacheDir := cfg.CacheDir
+	if cacheDir == "" {
+		u, err := user.Current()
+		if err != nil {
+			return err
+		}
+		cacheDir = filepath.Join(u.HomeDir, ".cache", "devpostdash")
 	}
-	cacheDir := filepath.Join(u.HomeDir, ".cache", "devpostdash")
 	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
 		return err
 	}
-	rawDevpostClient, err := devpost.New(ctx, &roundtrippers.Throttle{Transport: h, QPS: 1})
+	// The scheduler's per-host budget must not exceed the throttle.
+	qps := cfg.Devpost.QPS
+	strategies, err := devpost.ParseDiscovery(*discovery)
+	if err != nil {
+		return err
+	}
+	rawDevpostClient, err := devpost.New(ctx, &roundtrippers.Throttle{Transport: h, QPS: qps}, devpost.ClientOptions{GalleryConcurrency: *galleryConcurrency, Discovery: strategies, Referer: cfg.Devpost.Referer})
 	if err != nil {
 		return err
 	}
 	defer rawDevpostClient.Close()
-	// Refresh every 5 minutes, and cache for 1 hour.
-	d, err := devpost.NewCached(ctx, rawDevpostClient, 1*time.Hour, 5*time.Minute, filepath.Join(cacheDir, "devpost.json"))
+	store, err := openStore(cfg.Store, filepath.Join(cacheDir, cacheName))
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	d, err := devpost.NewCached(ctx, rawDevpostClient, time.Duration(cfg.Devpost.Freshness), time.Duration(cfg.Devpost.AutoRefresh), store, devpost.SchedulerOptions{Concurrency: cfg.Devpost.Concurrency, QPS: qps})
 	if err != nil {
 		return err
 	}
 	defer d.Close()
+	if err := d.Configure(ctx, cfg.cacheSettings()); err != nil {
+		return err
+	}
+
+	var hooks *devpost.Webhooks
+	if *webhooks != "" {
+		if hooks, err = loadWebhooks(*webhooks); err != nil {
+			return err
+		}
+		go hooks.Run(ctx, d)
+	}
 
 	if *dump != "" {
 		projects, err := d.FetchProjects(ctx, *dump)
@@ -247,29 +344,56 @@ func mainImpl() error {
 	}
 
 	var c genai.ProviderGen
-	if *provider != "" {
-		prov := providers.All[*provider]
+	if cfg.LLM.Provider != "" {
+		prov := providers.All[cfg.LLM.Provider]
 		if prov == nil {
-			return fmt.Errorf("unknown provider %q", *provider)
+			return fmt.Errorf("unknown provider %q", cfg.LLM.Provider)
 		}
 		f := func(h http.RoundTripper) http.RoundTripper {
-			return &roundtrippers.Throttle{Transport: h, QPS: 0.5}
+			return &roundtrippers.Throttle{Transport: h, QPS: cfg.LLM.QPS}
 		}
-		cl, err := prov(*model, f)
+		cl, err := prov(cfg.LLM.Model, f)
 		if err != nil {
 			return err
 		}
 		ok := false
 		if c, ok = cl.(genai.ProviderGen); !ok {
-			return fmt.Errorf("%T does not implement genai.ProviderGen", *provider)
+			return fmt.Errorf("%T does not implement genai.ProviderGen", cfg.LLM.Provider)
 		}
 	}
-	r, err := newRoaster(c, filepath.Join(cacheDir, "roaster.json"))
+	roastStore, err := openStore(cfg.Store, filepath.Join(cacheDir, "roaster"))
+	if err != nil {
+		return err
+	}
+	defer roastStore.Close()
+	r, err := newRoaster(c, roastStore)
 	if err != nil {
 		return err
 	}
 	defer r.Close()
-	return runWebserver(ctx, *host, d, r)
+
+	go preload(ctx, d, cfg)
+	groups := newGroupSet(cfg.Groups)
+	// running is the configuration in effect; the settings requiring a restart
+	// keep their initial value.
+	running := cfg
+	reloadOnHangup(ctx, func() error {
+		next, err := load()
+		if err != nil {
+			return err
+		}
+		if err := d.Configure(ctx, next.cacheSettings()); err != nil {
+			return err
+		}
+		groups.set(next.Groups)
+		for _, name := range running.restartNeeded(next) {
+			slog.WarnContext(ctx, "devpostdash", "msg", "restart to apply the new setting", "setting", name)
+		}
+		running = running.reload(next)
+		go preload(ctx, d, next)
+		return nil
+	})
+	return runWebserver(ctx, cfg.Host, d, r, hooks, groups)
 }
 
 func main() {
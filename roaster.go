@@ -6,11 +6,9 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
-	"os"
 	"slices"
 	"strings"
 	"sync"
@@ -20,64 +18,45 @@ import (
 	"github.com/maruel/genai"
 )
 
-type Roast struct {
-	Content     string    `json:"content"`
-	LastRefresh time.Time `json:"last_refresh"`
-	Hash        string    `json:"hash"`
-}
-
 type roaster struct {
-	llm       genai.ProviderGen
-	cacheFile string
+	llm   genai.ProviderGen
+	store devpost.Store
 
 	mu     sync.Mutex
-	roasts map[string]*Roast
+	roasts map[string]*devpost.Roast
 }
 
-func newRoaster(c genai.ProviderGen, cacheFile string) (*roaster, error) {
-	r := &roaster{llm: c, cacheFile: cacheFile, roasts: map[string]*Roast{}}
+// newRoaster returns a roaster persisting the roasts in store. The caller
+// keeps ownership of store and must close it after the roaster.
+func newRoaster(c genai.ProviderGen, store devpost.Store) (*roaster, error) {
+	r := &roaster{llm: c, store: store, roasts: map[string]*devpost.Roast{}}
 	err := r.loadCache()
 	return r, err
 }
 
 func (r *roaster) loadCache() error {
-	f, err := os.Open(r.cacheFile)
+	ids, err := r.store.ListRoasts()
 	defer func() {
-		slog.Info("web", "msg", "loaded cache", "err", err, "path", r.cacheFile, "roasts", len(r.roasts))
+		slog.Info("web", "msg", "loaded cache", "err", err, "roasts", len(r.roasts))
 	}()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
 		return err
 	}
-	defer f.Close()
-	data := serializedRoaster{}
-	if err = json.NewDecoder(f).Decode(&data); err != nil {
-		return err
+	roasts := make(map[string]*devpost.Roast, len(ids))
+	for _, id := range ids {
+		if roasts[id], err = r.store.GetRoast(id); err != nil {
+			return err
+		}
 	}
 	r.mu.Lock()
-	r.roasts = data.Roasts
+	r.roasts = roasts
 	r.mu.Unlock()
 	return nil
 }
 
 func (r *roaster) Close() error {
-	f, err := os.Create(r.cacheFile)
-	defer func() {
-		slog.Info("web", "msg", "saved cache", "err", err, "roasts", len(r.roasts))
-	}()
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-	e := json.NewEncoder(f)
-	e.SetIndent("", "  ")
-
-	r.mu.Lock()
-	data := serializedRoaster{Version: 1, Roasts: r.roasts}
-	err = e.Encode(&data)
-	r.mu.Unlock()
+	err := r.store.Flush()
+	slog.Info("web", "msg", "saved cache", "err", err, "roasts", len(r.roasts))
 	return err
 }
 
@@ -119,7 +98,7 @@ func (r *roaster) doRoast(ctx context.Context, p *devpost.Project) (string, erro
 		if err != nil {
 			return "", err
 		}
-		roast = &Roast{Content: resp.AsText(), LastRefresh: time.Now()}
+		roast = &devpost.Roast{Content: resp.AsText(), LastRefresh: time.Now()}
 		if roast.Content == "" {
 			return "", errors.New("no content generated")
 		}
@@ -128,13 +107,9 @@ func (r *roaster) doRoast(ctx context.Context, p *devpost.Project) (string, erro
 		r.mu.Lock()
 		r.roasts[p.ID] = roast
 		r.mu.Unlock()
+		if err := r.store.PutRoast(p.ID, roast); err != nil {
+			slog.ErrorContext(ctx, "roast", "msg", "failed to store roast", "err", err)
+		}
 	}
 	return roast.Content, nil
 }
-
-//
-
-type serializedRoaster struct {
-	Version int               `json:"version"`
-	Roasts  map[string]*Roast `json:"roasts"`
-}
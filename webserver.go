@@ -11,15 +11,20 @@ import (
 	"errors"
 	"fmt"
 	"html/template"
+	"io"
 	"io/fs"
 	"log/slog"
 	"net"
 	"net/http"
+	"net/url"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/maruel/devpostdash/devpost"
+	"golang.org/x/sync/errgroup"
 )
 
 //go:embed templates/*.html
@@ -39,8 +44,12 @@ func jsonMarshal(v any) (template.JS, error) {
 }
 
 type webserver struct {
-	d devpost.Client
+	d devpost.CachedClient
 	r *roaster
+	// hooks is nil when no webhook is configured.
+	hooks *devpost.Webhooks
+	// groups are the named event groups, served at /group/{name}.
+	groups *groupSet
 }
 
 func (s *webserver) handleRoot(w http.ResponseWriter, r *http.Request) {
@@ -76,49 +85,650 @@ func handleError(ctx context.Context, w http.ResponseWriter, err error) {
 		_, _ = w.Write(herr.Body)
 		return
 	}
+	if errors.Is(err, devpost.ErrCircuitOpen) {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
 	http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 }
 
+// handlePerson shows a participant's profile. The data is loaded from
+// /api/people/{username}.
+func (s *webserver) handlePerson(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	ctx := r.Context()
+	username := r.PathValue("username")
+	if err := templates.Lookup("page_person.html").Execute(w, map[string]any{"Title": username, "Username": username}); err != nil {
+		handleError(ctx, w, err)
+	}
+}
+
+// handleEvent renders the page_TYPE.html page of an event spec, i.e. one
+// event or several joined with "+".
 func (s *webserver) handleEvent(w http.ResponseWriter, r *http.Request) {
 	eventID := r.PathValue("eventID")
 	pageType := r.PathValue("type")
-	// Load the corresponding page_TYPE.html page under templates/.
-	tmpl := templates.Lookup("page_" + pageType + ".html")
-	if tmpl == nil {
+	if templates.Lookup("page_"+pageType+".html") == nil {
 		http.Redirect(w, r, "/event/"+eventID+"/card", http.StatusSeeOther)
 		return
 	}
+	ids, err := parseEvents(eventID)
+	if err != nil {
+		handleError(r.Context(), w, err)
+		return
+	}
+	s.renderEvents(w, r, ids, "", pageType)
+}
+
+func (s *webserver) handleGroupRedirect(w http.ResponseWriter, r *http.Request) {
+	http.Redirect(w, r, "/group/"+r.PathValue("name")+"/card", http.StatusSeeOther)
+}
 
+// handleGroup renders the page_TYPE.html page of a named event group.
+func (s *webserver) handleGroup(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	pageType := r.PathValue("type")
+	g, ok := s.groups.get(name)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if templates.Lookup("page_"+pageType+".html") == nil {
+		http.Redirect(w, r, "/group/"+name+"/card", http.StatusSeeOther)
+		return
+	}
+	title := g.Title
+	if title == "" {
+		title = name
+	}
+	s.renderEvents(w, r, g.Events, title, pageType)
+}
+
+// renderEvents renders the page_TYPE.html page with the union of the events'
+// projects. title overrides the title of the events if not empty.
+func (s *webserver) renderEvents(w http.ResponseWriter, r *http.Request, ids []string, title, pageType string) {
+	// Load the corresponding page_TYPE.html page under templates/.
+	tmpl := templates.Lookup("page_" + pageType + ".html")
 	ctx := r.Context()
-	out, err := s.getProjects(ctx, eventID)
+	spec := strings.Join(ids, "+")
+	out, err := s.getProjects(ctx, spec)
 	if err != nil {
 		handleError(ctx, w, err)
 		return
 	}
+	info := s.getEvents(ctx, ids, title)
+	challenge := r.URL.Query().Get("challenge")
 	data := map[string]any{
-		"Title":    eventID,
-		"EventID":  eventID,
-		"Projects": out,
+		"Title":      info.Title,
+		"EventID":    spec,
+		"Group":      len(ids) > 1,
+		"Event":      info,
+		"Challenge":  challenge,
+		"Challenges": listChallenges(info, out),
+		"Sort":       r.URL.Query().Get("sort"),
+		"Projects":   sortProjects(filterByChallenge(out, challenge), r.URL.Query().Get("sort")),
 	}
 	if err := tmpl.Execute(w, data); err != nil {
 		handleError(ctx, w, err)
 	}
 }
 
+// eventResponse is the response of /api/events/{eventID}.
+//
+// It used to be the list of projects only; the projects are now in Projects.
+type eventResponse struct {
+	Projects []*devpost.Project `json:"projects"`
+	Prizes   []award            `json:"prizes"`
+}
+
 func (s *webserver) apiEvent(w http.ResponseWriter, r *http.Request) {
 	eventID := r.PathValue("eventID")
 	ctx := r.Context()
-	out, err := s.getProjects(ctx, eventID)
+	projects, awards, err := s.getAwards(ctx, eventID)
 	if err != nil {
 		handleError(ctx, w, err)
 		return
 	}
+	out := eventResponse{
+		Projects: sortProjects(filterByChallenge(projects, r.URL.Query().Get("challenge")), r.URL.Query().Get("sort")),
+		Prizes:   awards,
+	}
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(out); err != nil {
 		handleError(ctx, w, err)
 	}
 }
 
+func (s *webserver) apiEventInfo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ids, err := parseEvents(r.PathValue("eventID"))
+	if err != nil {
+		handleError(ctx, w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(s.getEvents(ctx, ids, "")); err != nil {
+		handleError(ctx, w, err)
+	}
+}
+
+func (s *webserver) apiHistory(w http.ResponseWriter, r *http.Request) {
+	projectID := r.PathValue("projectID")
+	ctx := r.Context()
+	eventID, err := s.projectEvent(ctx, r.PathValue("eventID"), projectID)
+	if err != nil {
+		handleError(ctx, w, err)
+		return
+	}
+	history, err := s.d.FetchHistory(ctx, eventID, projectID)
+	if err != nil {
+		handleError(ctx, w, err)
+		return
+	}
+	if history == nil {
+		history = []devpost.Sample{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(history); err != nil {
+		handleError(ctx, w, err)
+	}
+}
+
+// apiChanges returns the changes after ?since=, which is a sequence number for
+// one event and a cursor like "a:12,b:3" for several events. The changes of
+// several events are sorted by time.
+func (s *webserver) apiChanges(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ids, err := parseEvents(r.PathValue("eventID"))
+	if err != nil {
+		handleError(ctx, w, err)
+		return
+	}
+	since, err := parseCursor(ids, r.URL.Query().Get("since"))
+	if err != nil {
+		handleError(ctx, w, err)
+		return
+	}
+	changes := []devpost.Change{}
+	for _, id := range ids {
+		c, err := s.d.FetchChanges(ctx, id, since[id])
+		if err != nil {
+			handleError(ctx, w, err)
+			return
+		}
+		changes = append(changes, c...)
+	}
+	if len(ids) > 1 {
+		sort.SliceStable(changes, func(i, j int) bool {
+			return changes[i].Time.Before(changes[j].Time)
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(changes); err != nil {
+		handleError(ctx, w, err)
+	}
+}
+
+// feedComment is one entry of the comments feed of an event.
+type feedComment struct {
+	devpost.Comment
+	// Kind is "comment" or "update".
+	Kind         string `json:"kind"`
+	EventID      string `json:"event_id"`
+	ProjectID    string `json:"project_id"`
+	ProjectTitle string `json:"project_title"`
+	ProjectURL   string `json:"project_url"`
+}
+
+// latestComments returns the comments and updates of the projects, newest
+// first, at most limit.
+func latestComments(projects []*devpost.Project, limit int) []feedComment {
+	out := []feedComment{}
+	for _, p := range projects {
+		add := func(kind string, comments []devpost.Comment) {
+			for _, c := range comments {
+				out = append(out, feedComment{Comment: c, Kind: kind, EventID: p.EventID, ProjectID: p.ID, ProjectTitle: p.Title, ProjectURL: p.URL})
+			}
+		}
+		add("comment", p.Comments)
+		add("update", p.Updates)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Time.After(out[j].Time)
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// apiComments returns the latest comments and updates of the event's
+// projects, newest first. ?limit= defaults to 50.
+func (s *webserver) apiComments(w http.ResponseWriter, r *http.Request) {
+	eventID := r.PathValue("eventID")
+	ctx := r.Context()
+	limit := 50
+	if v := r.URL.Query().Get("limit"); v != "" {
+		var err error
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+			handleError(ctx, w, &devpost.HTTPError{StatusCode: http.StatusBadRequest, Body: []byte("invalid limit")})
+			return
+		}
+	}
+	projects, err := s.getProjects(ctx, eventID)
+	if err != nil {
+		handleError(ctx, w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(latestComments(projects, limit)); err != nil {
+		handleError(ctx, w, err)
+	}
+}
+
+// sseKeepAlive is the interval at which a comment is sent on idle streams so
+// proxies do not close the connection.
+const sseKeepAlive = 30 * time.Second
+
+// streamChange is the payload of a "change" server-sent event.
+type streamChange struct {
+	devpost.Change
+	Project *devpost.Project `json:"project,omitempty"`
+}
+
+// apiStream streams the changes to the projects as server-sent events.
+//
+// A "snapshot" event with the full list of projects is sent first, unless the
+// client resumes with Last-Event-ID or ?since=. It is sent again if the
+// change log was truncated past the resume point or doesn't reach it.
+//
+// For several events, the IDs of the server-sent events are cursors covering
+// all the events. See cursor.
+func (s *webserver) apiStream(w http.ResponseWriter, r *http.Request) {
+	eventID := r.PathValue("eventID")
+	challenge := r.URL.Query().Get("challenge")
+	ctx := r.Context()
+	ids, err := parseEvents(eventID)
+	if err != nil {
+		handleError(ctx, w, err)
+		return
+	}
+	v := r.Header.Get("Last-Event-ID")
+	if v == "" {
+		v = r.URL.Query().Get("since")
+	}
+	since, err := parseCursor(ids, v)
+	if err != nil {
+		handleError(ctx, w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	rc := http.NewResponseController(w)
+	if since != nil {
+		// The client is ahead of the server when the change log was lost, e.g.
+		// the cache was deleted, resync.
+		latest, err := s.latestSeqs(ctx, ids)
+		if err != nil {
+			handleError(ctx, w, err)
+			return
+		}
+		if ahead(since, latest) {
+			since = nil
+		}
+	}
+	if since == nil {
+		if since, err = s.sendSnapshot(ctx, w, ids, challenge); err != nil {
+			handleError(ctx, w, err)
+			return
+		}
+		_ = rc.Flush()
+	}
+	for {
+		waitCtx, cancel := context.WithTimeout(ctx, sseKeepAlive)
+		changes, err := waitChanges(waitCtx, s.d, ids, since)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			// Keep the events requested while they are on screen, so they are
+			// refreshed more often.
+			for _, id := range ids {
+				_, _ = s.d.FetchProjects(ctx, id)
+			}
+			_, err = io.WriteString(w, ": keep-alive\n\n")
+		} else if err == nil && truncated(changes, since) {
+			// The log was truncated past the resume point, resync.
+			since, err = s.sendSnapshot(ctx, w, ids, challenge)
+		} else if err == nil {
+			err = s.sendChanges(ctx, w, ids, challenge, changes, since)
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "web", "msg", "stream failed", "eventID", eventID, "err", err)
+			return
+		}
+		if err = rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// truncated returns true if the change log of an event was truncated past the
+// cursor.
+func truncated(changes map[string][]devpost.Change, since cursor) bool {
+	for id, c := range changes {
+		if len(c) != 0 && c[0].Seq > since[id]+1 {
+			return true
+		}
+	}
+	return false
+}
+
+// ahead returns true if the cursor is past the latest change of an event.
+func ahead(since, latest cursor) bool {
+	for id, seq := range latest {
+		if since[id] > seq {
+			return true
+		}
+	}
+	return false
+}
+
+// latestSeqs returns the sequence number of the latest change of each event.
+func (s *webserver) latestSeqs(ctx context.Context, ids []string) (cursor, error) {
+	out := cursor{}
+	for _, id := range ids {
+		changes, err := s.d.FetchChanges(ctx, id, 0)
+		if err != nil {
+			return nil, err
+		}
+		out[id] = 0
+		if n := len(changes); n != 0 {
+			out[id] = changes[n-1].Seq
+		}
+	}
+	return out, nil
+}
+
+// sendSnapshot sends the full list of projects and returns the cursor to
+// resume from.
+func (s *webserver) sendSnapshot(ctx context.Context, w io.Writer, ids []string, challenge string) (cursor, error) {
+	// Get the sequence numbers first; the changes that happen in between will
+	// be sent again, which is harmless.
+	since, err := s.latestSeqs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	projects, err := s.getProjects(ctx, strings.Join(ids, "+"))
+	if err != nil {
+		return nil, err
+	}
+	return since, writeSSE(w, since.format(ids), "snapshot", filterByChallenge(projects, challenge))
+}
+
+// sendChanges sends the changes along the current state of the projects and
+// advances the cursor.
+func (s *webserver) sendChanges(ctx context.Context, w io.Writer, ids []string, challenge string, changes map[string][]devpost.Change, since cursor) error {
+	projects, err := s.getProjects(ctx, strings.Join(ids, "+"))
+	if err != nil {
+		return err
+	}
+	// A project can be submitted to several of the events.
+	byID := make(map[string]*devpost.Project, len(projects))
+	for _, p := range projects {
+		byID[p.EventID+"/"+p.ID] = p
+	}
+	for _, id := range ids {
+		for _, c := range changes[id] {
+			since[id] = c.Seq
+			p := byID[id+"/"+c.ProjectID]
+			if c.Type != devpost.ChangeRemoved && (p == nil || (challenge != "" && !slices.Contains(p.Challenges, challenge))) {
+				continue
+			}
+			if err := writeSSE(w, since.format(ids), "change", streamChange{Change: c, Project: p}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func writeSSE(w io.Writer, id, event string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", id, event, b)
+	return err
+}
+
+// award is a prize along the projects that won it.
+type award struct {
+	devpost.Prize
+	EventID string   `json:"event_id"`
+	Winners []string `json:"winners"`
+}
+
+func (s *webserver) apiPrizes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	_, out, err := s.getAwards(ctx, r.PathValue("eventID"))
+	if err != nil {
+		handleError(ctx, w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		handleError(ctx, w, err)
+	}
+}
+
+// getAwards returns the projects of an event spec and its prizes with the
+// projects that won them.
+//
+// The prizes won by a project are only listed on its page, which is refreshed
+// in the background, so a winner whose page wasn't fetched yet isn't listed.
+// The prizes are best effort like getEvent: the prizes of an event that
+// can't be fetched are skipped.
+func (s *webserver) getAwards(ctx context.Context, eventID string) ([]*devpost.Project, []award, error) {
+	projects, err := s.getProjects(ctx, eventID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if eventID == "mock" {
+		return projects, []award{}, nil
+	}
+	ids, err := parseEvents(eventID)
+	if err != nil {
+		return nil, nil, err
+	}
+	out := []award{}
+	for _, id := range ids {
+		prizes, err := s.d.FetchPrizes(ctx, id)
+		if err != nil {
+			slog.WarnContext(ctx, "web", "msg", "failed to fetch prizes", "eventID", id, "err", err)
+			continue
+		}
+		for _, p := range prizes {
+			// Different events can have prizes with the same name.
+			a := award{Prize: p, EventID: id, Winners: []string{}}
+			for _, project := range projects {
+				if project.EventID == id && slices.ContainsFunc(project.Prizes, func(name string) bool { return samePrize(name, p.Name) }) {
+					a.Winners = append(a.Winners, project.ID)
+				}
+			}
+			out = append(out, a)
+		}
+	}
+	return projects, out, nil
+}
+
+// samePrize returns true if the prize names are the same, ignoring the case
+// and the spacing that differ between the prizes page and the project pages.
+func samePrize(a, b string) bool {
+	return strings.EqualFold(strings.Join(strings.Fields(a), " "), strings.Join(strings.Fields(b), " "))
+}
+
+// apiScheduler returns the queue of background refreshes.
+func (s *webserver) apiScheduler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	tasks, err := s.d.RefreshQueue(ctx)
+	if err != nil {
+		handleError(ctx, w, err)
+		return
+	}
+	if tasks == nil {
+		tasks = []devpost.ScheduledTask{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(tasks); err != nil {
+		handleError(ctx, w, err)
+	}
+}
+
+// apiUpstream returns the health of devpost.
+func (s *webserver) apiUpstream(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	status, err := s.d.Upstream(ctx)
+	if err != nil {
+		handleError(ctx, w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		handleError(ctx, w, err)
+	}
+}
+
+// apiWebhooks returns the webhook delivery log, oldest first. The path of the
+// URLs is redacted since it usually holds the webhook's token.
+func (s *webserver) apiWebhooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	out := []devpost.Delivery{}
+	if s.hooks != nil {
+		out = s.hooks.Deliveries()
+	}
+	for i := range out {
+		if u, err := url.Parse(out[i].URL); err == nil {
+			out[i].URL = u.Scheme + "://" + u.Host + "/…"
+		} else {
+			out[i].URL = ""
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		handleError(ctx, w, err)
+	}
+}
+
+// apiScraperHealth returns how well the latest project lists parsed. It
+// responds 503 when a parse looks broken so it can be monitored.
+func (s *webserver) apiScraperHealth(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	health, err := s.d.ScraperHealth(ctx)
+	if err != nil {
+		handleError(ctx, w, err)
+		return
+	}
+	if health == nil {
+		health = &devpost.ScraperHealth{Healthy: true}
+	}
+	if health.Events == nil {
+		health.Events = []devpost.ScrapeCheck{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if !health.Healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(health); err != nil {
+		slog.ErrorContext(ctx, "web", "msg", "failed to encode", "err", err)
+	}
+}
+
+// apiEventPeople returns the participants of the events. A person in several
+// of the events is listed once with all their projects.
+func (s *webserver) apiEventPeople(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	ids, err := parseEvents(r.PathValue("eventID"))
+	if err != nil {
+		handleError(ctx, w, err)
+		return
+	}
+	people := []*devpost.Participant{}
+	byName := map[string]*devpost.Participant{}
+	for _, id := range ids {
+		p, err := s.d.FetchPeople(ctx, id)
+		if err != nil {
+			handleError(ctx, w, err)
+			return
+		}
+		for _, pp := range p {
+			key := strings.ToLower(pp.Username)
+			if prev := byName[key]; prev != nil {
+				prev.Projects = append(prev.Projects, pp.Projects...)
+				continue
+			}
+			byName[key] = pp
+			people = append(people, pp)
+		}
+	}
+	if len(ids) > 1 {
+		sort.SliceStable(people, func(i, j int) bool {
+			return strings.ToLower(people[i].Name) < strings.ToLower(people[j].Name)
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(people); err != nil {
+		handleError(ctx, w, err)
+	}
+}
+
+// apiHackathons searches the hackathons listed on devpost.com.
+//
+// ?q= is the search terms, ?status= and ?theme= can be repeated and ?page= is
+// 1-based.
+func (s *webserver) apiHackathons(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	v := r.URL.Query()
+	q := devpost.EventQuery{Search: v.Get("q"), Status: v["status"], Themes: v["theme"]}
+	if p := v.Get("page"); p != "" {
+		var err error
+		if q.Page, err = strconv.Atoi(p); err != nil || q.Page <= 0 {
+			handleError(ctx, w, &devpost.HTTPError{StatusCode: http.StatusBadRequest, Body: []byte("invalid page")})
+			return
+		}
+	}
+	out, err := s.d.ListEvents(ctx, q)
+	if err != nil {
+		handleError(ctx, w, err)
+		return
+	}
+	if out == nil {
+		out = []devpost.Hackathon{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		handleError(ctx, w, err)
+	}
+}
+
+// apiPerson returns a participant's projects across the events and profile.
+func (s *webserver) apiPerson(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	p, err := s.d.FetchPerson(ctx, r.PathValue("username"))
+	if err != nil {
+		handleError(ctx, w, err)
+		return
+	}
+	if p.Projects == nil {
+		p.Projects = []devpost.ParticipantProject{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		handleError(ctx, w, err)
+	}
+}
+
 func (s *webserver) apiRoast(w http.ResponseWriter, r *http.Request) {
 	var roastReq struct {
 		EventID   string `json:"event_id"`
@@ -145,29 +755,115 @@ func (s *webserver) apiRoast(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// getEvents returns the metadata of the events, merged if there are several.
+// title overrides the title of the events if not empty.
+func (s *webserver) getEvents(ctx context.Context, ids []string, title string) *devpost.EventInfo {
+	infos := make([]*devpost.EventInfo, len(ids))
+	for i, id := range ids {
+		infos[i] = s.getEvent(ctx, id)
+	}
+	info := mergeInfo(infos)
+	if title != "" {
+		info.Title = title
+	}
+	return info
+}
+
+// getEvent returns the event metadata. It never fails; the landing page is
+// only decorative so on error the event ID is used as the title.
+func (s *webserver) getEvent(ctx context.Context, eventID string) *devpost.EventInfo {
+	if eventID != "mock" {
+		info, err := s.d.FetchEvent(ctx, eventID)
+		if err == nil {
+			info2 := *info
+			info2.LastRefresh = time.Time{}
+			if info2.Title == "" {
+				info2.Title = eventID
+			}
+			return &info2
+		}
+		slog.WarnContext(ctx, "web", "msg", "failed to fetch event info", "eventID", eventID, "err", err)
+	}
+	return &devpost.EventInfo{ID: eventID, Title: eventID}
+}
+
+// getProjects returns the union of the projects of an event spec, sorted by
+// likes. Each project is tagged with its event.
 func (s *webserver) getProjects(ctx context.Context, eventID string) ([]*devpost.Project, error) {
 	if eventID == "mock" {
 		return devpostProjects, nil
 	}
-	projects, err := s.d.FetchProjects(ctx, eventID)
+	ids, err := parseEvents(eventID)
 	if err != nil {
 		return nil, err
 	}
-	sort.Slice(projects, func(i, j int) bool {
-		return projects[i].Likes > projects[j].Likes
-	})
-	out := make([]*devpost.Project, 0, len(projects))
-	for _, p := range projects {
-		p2 := *p
-		p2.LastRefresh = time.Time{}
-		out = append(out, &p2)
+	all := make([][]*devpost.Project, len(ids))
+	eg, ctx2 := errgroup.WithContext(ctx)
+	for i, id := range ids {
+		eg.Go(func() error {
+			var err error
+			all[i], err = s.d.FetchProjects(ctx2, id)
+			return err
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	var out []*devpost.Project
+	for i, projects := range all {
+		for _, p := range projects {
+			p.EventID = ids[i]
+			p.LastRefresh = time.Time{}
+			out = append(out, p)
+		}
 	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Likes > out[j].Likes
+	})
 	if len(out) == 0 {
 		out = devpostProjects
 	}
 	return out, nil
 }
 
+// filterByChallenge returns the projects submitted to the challenge. An empty
+// challenge returns all the projects.
+func filterByChallenge(projects []*devpost.Project, challenge string) []*devpost.Project {
+	if challenge == "" {
+		return projects
+	}
+	out := make([]*devpost.Project, 0, len(projects))
+	for _, p := range projects {
+		if slices.Contains(p.Challenges, challenge) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// sortProjects returns the projects sorted by order. "trending" sorts by likes
+// velocity, otherwise the order is kept.
+func sortProjects(projects []*devpost.Project, order string) []*devpost.Project {
+	if order == "trending" {
+		projects = slices.Clone(projects)
+		sort.SliceStable(projects, func(i, j int) bool {
+			return projects[i].LikesVelocity > projects[j].LikesVelocity
+		})
+	}
+	return projects
+}
+
+// listChallenges returns the sorted union of the event's challenges and the
+// ones the projects were submitted to.
+func listChallenges(info *devpost.EventInfo, projects []*devpost.Project) []string {
+	out := slices.Clone(info.Challenges)
+	for _, p := range projects {
+		out = append(out, p.Challenges...)
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
+
 var devpostProjects = []*devpost.Project{
 	{
 		ID:        "-1",
@@ -213,6 +909,10 @@ func (s *webserver) getProject(ctx context.Context, eventID, projectID string) (
 	if projectID == "-2" {
 		return devpostProjects[1], nil
 	}
+	eventID, err := s.projectEvent(ctx, eventID, projectID)
+	if err != nil {
+		return nil, err
+	}
 	projects, err := s.d.FetchProjects(ctx, eventID)
 	if err != nil {
 		return nil, err
@@ -231,10 +931,34 @@ func (s *webserver) getProject(ctx context.Context, eventID, projectID string) (
 		}
 	}
 	// Refresh description and tags for the single project
-	if err := s.d.FetchProject(ctx, p); err != nil {
-		return nil, err
+	return s.d.FetchProject(ctx, p)
+}
+
+// projectEvent returns the event of the project in an event spec. A project
+// submitted to several of the events must be requested with its own event.
+func (s *webserver) projectEvent(ctx context.Context, eventID, projectID string) (string, error) {
+	ids, err := parseEvents(eventID)
+	if err != nil || len(ids) == 1 {
+		return eventID, err
+	}
+	projects, err := s.getProjects(ctx, eventID)
+	if err != nil {
+		return "", err
+	}
+	var found []string
+	for _, p := range projects {
+		if p.ID == projectID {
+			found = append(found, p.EventID)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return "", &devpost.HTTPError{StatusCode: http.StatusNotFound, Body: []byte(fmt.Sprintf("project %q not found", eventID+"/"+projectID))}
+	case 1:
+		return found[0], nil
+	default:
+		return "", &devpost.HTTPError{StatusCode: http.StatusBadRequest, Body: []byte(fmt.Sprintf("project %q is in the events %s, use one of them", projectID, strings.Join(found, ", ")))}
 	}
-	return p, nil
 }
 
 func loggingMiddleware(next http.Handler) http.Handler {
@@ -282,15 +1006,31 @@ func getRealIP(r *http.Request) net.IP {
 	return nil
 }
 
-func newWebServerHandler(d devpost.Client, r *roaster) http.Handler {
-	w := &webserver{d: d, r: r}
+func newWebServerHandler(d devpost.CachedClient, r *roaster, hooks *devpost.Webhooks, groups *groupSet) http.Handler {
+	w := &webserver{d: d, r: r, hooks: hooks, groups: groups}
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("GET /{$}", w.handleRoot)
 	mux.HandleFunc("GET /about", w.handleAbout)
 	mux.HandleFunc("GET /event/{eventID}", w.handleEventRedirect)
 	mux.HandleFunc("GET /event/{eventID}/{type}", w.handleEvent)
+	mux.HandleFunc("GET /group/{name}", w.handleGroupRedirect)
+	mux.HandleFunc("GET /group/{name}/{type}", w.handleGroup)
+	mux.HandleFunc("GET /people/{username}", w.handlePerson)
 	mux.HandleFunc("GET /api/events/{eventID}", w.apiEvent)
+	mux.HandleFunc("GET /api/events/{eventID}/info", w.apiEventInfo)
+	mux.HandleFunc("GET /api/events/{eventID}/prizes", w.apiPrizes)
+	mux.HandleFunc("GET /api/events/{eventID}/changes", w.apiChanges)
+	mux.HandleFunc("GET /api/events/{eventID}/comments", w.apiComments)
+	mux.HandleFunc("GET /api/events/{eventID}/people", w.apiEventPeople)
+	mux.HandleFunc("GET /api/events/{eventID}/stream", w.apiStream)
+	mux.HandleFunc("GET /api/events/{eventID}/projects/{projectID}/history", w.apiHistory)
+	mux.HandleFunc("GET /api/people/{username}", w.apiPerson)
+	mux.HandleFunc("GET /api/hackathons", w.apiHackathons)
+	mux.HandleFunc("GET /api/scheduler", w.apiScheduler)
+	mux.HandleFunc("GET /api/upstream", w.apiUpstream)
+	mux.HandleFunc("GET /api/health/scraper", w.apiScraperHealth)
+	mux.HandleFunc("GET /api/webhooks", w.apiWebhooks)
 	mux.HandleFunc("POST /api/roast", w.apiRoast)
 	staticContent, err := fs.Sub(staticFS, "static")
 	if err != nil {
@@ -300,15 +1040,20 @@ func newWebServerHandler(d devpost.Client, r *roaster) http.Handler {
 	return loggingMiddleware(mux)
 }
 
-func runWebserver(ctx context.Context, host string, d devpost.Client, r *roaster) error {
-	handler := newWebServerHandler(d, r)
+func runWebserver(ctx context.Context, host string, d devpost.CachedClient, r *roaster, hooks *devpost.Webhooks, groups *groupSet) error {
+	handler := newWebServerHandler(d, r, hooks, groups)
 	lc := net.ListenConfig{}
 	ln, err := lc.Listen(ctx, "tcp", host)
 	if err != nil {
 		return err
 	}
 	slog.InfoContext(ctx, "web", "listening", ln.Addr())
-	s := &http.Server{Handler: handler, ReadHeaderTimeout: 2 * time.Second}
+	s := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 2 * time.Second,
+		// Cancel the long lived streams on shutdown.
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
 	errCh := make(chan error)
 	go func() {
 		err2 := s.Serve(ln)
@@ -1,19 +1,35 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/maruel/devpostdash/devpost"
+	"github.com/maruel/devpostdash/devpost/devposttest"
 )
 
 // mockDevpostClient implements devpostClientInterface for testing.
 type mockDevpostClient struct{}
 
+func (m *mockDevpostClient) FetchEvent(ctx context.Context, eventID string) (*devpost.EventInfo, error) {
+	return &devpost.EventInfo{ID: eventID, Title: "Fake Event", Tagline: "A fake hackathon."}, nil
+}
+
+func (m *mockDevpostClient) FetchPrizes(ctx context.Context, eventID string) ([]devpost.Prize, error) {
+	return nil, nil
+}
+
 func (m *mockDevpostClient) FetchProjects(ctx context.Context, eventID string) ([]*devpost.Project, error) {
 	if eventID == "fake-event" {
 		return []*devpost.Project{
@@ -44,8 +60,63 @@ func (m *mockDevpostClient) FetchProjects(ctx context.Context, eventID string) (
 	return nil, nil
 }
 
-func (m *mockDevpostClient) FetchProject(ctx context.Context, p *devpost.Project) error {
+func (m *mockDevpostClient) FetchProject(ctx context.Context, p *devpost.Project) (*devpost.Project, error) {
 	// No-op for this test
+	return p, nil
+}
+
+func (m *mockDevpostClient) FetchHistory(ctx context.Context, eventID, projectID string) ([]devpost.Sample, error) {
+	return nil, nil
+}
+
+func (m *mockDevpostClient) FetchChanges(ctx context.Context, eventID string, since int64) ([]devpost.Change, error) {
+	return nil, nil
+}
+
+func (m *mockDevpostClient) WaitChanges(ctx context.Context, eventID string, since int64) ([]devpost.Change, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (m *mockDevpostClient) RefreshQueue(ctx context.Context) ([]devpost.ScheduledTask, error) {
+	return nil, nil
+}
+
+func (m *mockDevpostClient) Upstream(ctx context.Context) (*devpost.UpstreamStatus, error) {
+	return &devpost.UpstreamStatus{State: devpost.BreakerClosed}, nil
+}
+
+func (m *mockDevpostClient) ScraperHealth(ctx context.Context) (*devpost.ScraperHealth, error) {
+	return &devpost.ScraperHealth{Healthy: true}, nil
+}
+
+func (m *mockDevpostClient) FetchPeople(ctx context.Context, eventID string) ([]*devpost.Participant, error) {
+	p, err := m.FetchPerson(ctx, "alice")
+	return []*devpost.Participant{p}, err
+}
+
+func (m *mockDevpostClient) FetchPerson(ctx context.Context, username string) (*devpost.Participant, error) {
+	if username != "alice" {
+		return nil, &devpost.HTTPError{StatusCode: http.StatusNotFound, Body: []byte("unknown person")}
+	}
+	return &devpost.Participant{
+		Person:   devpost.Person{Name: "Alice", URL: "https://devpost.com/alice"},
+		Username: "alice",
+		Projects: []devpost.ParticipantProject{{EventID: "fake-event", ProjectID: "1", Title: "Fake Project One"}},
+	}, nil
+}
+
+func (m *mockDevpostClient) ListEvents(ctx context.Context, q devpost.EventQuery) ([]devpost.Hackathon, error) {
+	out := []devpost.Hackathon{
+		{EventInfo: devpost.EventInfo{ID: "fake-event", Title: "Fake Event"}, Status: devpost.StatusOpen},
+		{EventInfo: devpost.EventInfo{ID: "old-event", Title: "Old Event"}, Status: devpost.StatusEnded},
+	}
+	return slices.DeleteFunc(out, func(h devpost.Hackathon) bool {
+		return (len(q.Status) != 0 && !slices.Contains(q.Status, h.Status)) || !strings.Contains(strings.ToLower(h.Title), strings.ToLower(q.Search))
+	}), nil
+}
+
+func (m *mockDevpostClient) Configure(ctx context.Context, s devpost.CacheSettings) error {
 	return nil
 }
 
@@ -55,7 +126,7 @@ func (m *mockDevpostClient) Close() error {
 
 func TestHandleEventCards(t *testing.T) {
 	mockClient := &mockDevpostClient{}
-	handler := newWebServerHandler(mockClient, nil) // Pass nil for roaster as it's not used in this test
+	handler := newWebServerHandler(mockClient, nil, nil, nil) // Pass nil for roaster as it's not used in this test
 
 	ts := httptest.NewServer(handler)
 	defer ts.Close()
@@ -83,3 +154,311 @@ func TestHandleEventCards(t *testing.T) {
 		t.Errorf("Response body does not contain 'Fake Project Two'")
 	}
 }
+
+// TestHandleEventOffline renders an event fetched from the fake devpost.com
+// through the cached client.
+func TestHandleEventOffline(t *testing.T) {
+	srv, err := devposttest.NewServer(os.DirFS(filepath.Join("devpost", "devposttest", "testdata")), devposttest.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	raw, err := srv.Client(t.Context(), devpost.ClientOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer raw.Close()
+	store, err := devpost.NewFileStore(filepath.Join(t.TempDir(), "store.json"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	d, err := devpost.NewCached(t.Context(), raw, time.Hour, 5*time.Minute, store, devpost.SchedulerOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	ts := httptest.NewServer(newWebServerHandler(d, nil, nil, nil))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/event/vibe/cards")
+	if err != nil {
+		t.Fatalf("Failed to make GET request: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read response body: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status OK, got %d: %s", resp.StatusCode, body)
+	}
+	for _, want := range []string{"Vibe Coding Hackathon", "Vibe Check", "Lofi Linter", "Prompt Golf"} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("Response body does not contain %q", want)
+		}
+	}
+}
+
+func TestFilterByChallenge(t *testing.T) {
+	projects := []*devpost.Project{
+		{ID: "1", Challenges: []string{"Web"}},
+		{ID: "2", Challenges: []string{"Mobile", "Web"}},
+		{ID: "3"},
+	}
+	if got := filterByChallenge(projects, ""); len(got) != 3 {
+		t.Errorf("Expected 3 projects, got %d", len(got))
+	}
+	if got := filterByChallenge(projects, "Mobile"); len(got) != 1 || got[0].ID != "2" {
+		t.Errorf("Unexpected projects for Mobile: %v", got)
+	}
+	if got := listChallenges(&devpost.EventInfo{Challenges: []string{"Web", "AI"}}, projects); strings.Join(got, ",") != "AI,Mobile,Web" {
+		t.Errorf("Unexpected challenges: %v", got)
+	}
+}
+
+func TestLatestComments(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 6, d, 0, 0, 0, 0, time.UTC) }
+	projects := []*devpost.Project{
+		{ID: "1", Comments: []devpost.Comment{{ID: "a", Time: day(1)}, {ID: "c", Time: day(3)}}},
+		{ID: "2", Comments: []devpost.Comment{{ID: "b", Time: day(2)}}, Updates: []devpost.Comment{{ID: "d", Time: day(4)}}},
+		{ID: "3"},
+	}
+	got := latestComments(projects, 3)
+	var ids []string
+	for _, c := range got {
+		ids = append(ids, c.ProjectID+"/"+c.ID)
+	}
+	if strings.Join(ids, ",") != "2/d,1/c,2/b" || got[0].Kind != "update" || got[1].Kind != "comment" {
+		t.Errorf("Unexpected comments %+v", got)
+	}
+	if got := latestComments(projects[2:], 10); got == nil || len(got) != 0 {
+		t.Errorf("Expected an empty list, got %#v", got)
+	}
+}
+
+func TestAPIPeople(t *testing.T) {
+	ts := httptest.NewServer(newWebServerHandler(&mockDevpostClient{}, nil, nil, nil))
+	defer ts.Close()
+
+	for path, want := range map[string]int{
+		"/api/events/fake-event/people": http.StatusOK,
+		"/api/people/alice":             http.StatusOK,
+		"/api/people/bob":               http.StatusNotFound,
+		"/people/alice":                 http.StatusOK,
+	} {
+		resp, err := http.Get(ts.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != want {
+			t.Errorf("%s: Expected status %d, got %d", path, want, resp.StatusCode)
+		}
+		if want == http.StatusOK && !strings.Contains(string(body), "alice") {
+			t.Errorf("%s: Unexpected body %q", path, body)
+		}
+	}
+}
+
+func TestAPIHackathons(t *testing.T) {
+	ts := httptest.NewServer(newWebServerHandler(&mockDevpostClient{}, nil, nil, nil))
+	defer ts.Close()
+
+	for _, tc := range []struct {
+		query string
+		code  int
+		want  string
+	}{
+		{"", http.StatusOK, "fake-event,old-event"},
+		{"?status=open", http.StatusOK, "fake-event"},
+		{"?q=old&status=open&status=ended", http.StatusOK, "old-event"},
+		{"?q=nothing", http.StatusOK, ""},
+		{"?page=0", http.StatusBadRequest, ""},
+	} {
+		resp, err := http.Get(ts.URL + "/api/hackathons" + tc.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []devpost.Hackathon
+		if resp.StatusCode == http.StatusOK {
+			err = json.NewDecoder(resp.Body).Decode(&got)
+		}
+		_ = resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != tc.code {
+			t.Errorf("%q: Expected status %d, got %d", tc.query, tc.code, resp.StatusCode)
+			continue
+		}
+		var ids []string
+		for _, h := range got {
+			ids = append(ids, h.ID)
+		}
+		if strings.Join(ids, ",") != tc.want {
+			t.Errorf("%q: got %v, want %s", tc.query, ids, tc.want)
+		}
+	}
+}
+
+// prizesClient has the page of the winner cached.
+type prizesClient struct {
+	mockDevpostClient
+	// err is returned by FetchPrizes.
+	err     error
+	fetched atomic.Bool
+}
+
+func (c *prizesClient) FetchPrizes(ctx context.Context, eventID string) ([]devpost.Prize, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return []devpost.Prize{{Name: "Grand  prize"}, {Name: "Runner Up"}}, nil
+}
+
+func (c *prizesClient) FetchProjects(ctx context.Context, eventID string) ([]*devpost.Project, error) {
+	projects, err := c.mockDevpostClient.FetchProjects(ctx, eventID)
+	for _, p := range projects {
+		if p.Winner {
+			p.Prizes = []string{"Grand Prize"}
+		}
+	}
+	return projects, err
+}
+
+func (c *prizesClient) FetchProject(ctx context.Context, p *devpost.Project) (*devpost.Project, error) {
+	c.fetched.Store(true)
+	return p, nil
+}
+
+func TestAPIEventPrizes(t *testing.T) {
+	get := func(c *prizesClient) eventResponse {
+		ts := httptest.NewServer(newWebServerHandler(c, nil, nil, nil))
+		defer ts.Close()
+		resp, err := http.Get(ts.URL + "/api/events/fake-event")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Expected status OK, got %d", resp.StatusCode)
+		}
+		var got eventResponse
+		if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	c := &prizesClient{}
+	got := get(c)
+	if len(got.Projects) != 2 || len(got.Prizes) != 2 {
+		t.Fatalf("Unexpected response %+v", got)
+	}
+	if w := got.Prizes[0].Winners; len(w) != 1 || w[0] != "2" {
+		t.Errorf("Unexpected winners %v", w)
+	}
+	if w := got.Prizes[1].Winners; len(w) != 0 {
+		t.Errorf("Unexpected winners %v", w)
+	}
+	// The winners are read from the cache.
+	if c.fetched.Load() {
+		t.Error("Expected no project page fetch")
+	}
+
+	// The projects are still served when the prizes can't be fetched.
+	got = get(&prizesClient{err: devpost.ErrCircuitOpen})
+	if len(got.Projects) != 2 || got.Prizes == nil || len(got.Prizes) != 0 {
+		t.Errorf("Unexpected response %+v", got)
+	}
+}
+
+func TestAPIStreamSnapshot(t *testing.T) {
+	ts := httptest.NewServer(newWebServerHandler(&mockDevpostClient{}, nil, nil, nil))
+	defer ts.Close()
+
+	// A client ahead of the server, e.g. after the cache was deleted, gets a
+	// snapshot too.
+	for _, lastEventID := range []string{"", "7"} {
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, "GET", ts.URL+"/api/events/fake-event/stream", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to make GET request: %v", err)
+		}
+		defer resp.Body.Close()
+		if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Errorf("Unexpected Content-Type %q", ct)
+		}
+		var lines []string
+		for s := bufio.NewScanner(resp.Body); s.Scan() && s.Text() != ""; {
+			lines = append(lines, s.Text())
+		}
+		if len(lines) != 3 || lines[0] != "id: 0" || lines[1] != "event: snapshot" {
+			t.Fatalf("%q: Unexpected event: %q", lastEventID, lines)
+		}
+		if !strings.Contains(lines[2], "Fake Project One") {
+			t.Errorf("%q: Snapshot does not contain 'Fake Project One'", lastEventID)
+		}
+	}
+}
+
+// webhookClient reports one new project.
+type webhookClient struct {
+	mockDevpostClient
+}
+
+func (c *webhookClient) WaitChanges(ctx context.Context, eventID string, since int64) ([]devpost.Change, error) {
+	if since < 1 {
+		return []devpost.Change{{Seq: 1, Type: devpost.ChangeAdded, EventID: eventID, ProjectID: "1", Title: "Fake Project One"}}, nil
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestAPIWebhooks(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer target.Close()
+	hooks, err := devpost.NewWebhooks(http.DefaultTransport, []devpost.Webhook{{EventID: "fake-event", URL: target.URL + "/secret-token"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &webhookClient{}
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	go hooks.Run(ctx, c)
+	ts := httptest.NewServer(newWebServerHandler(c, nil, hooks, nil))
+	defer ts.Close()
+
+	var got []devpost.Delivery
+	for start := time.Now(); len(got) == 0 && time.Since(start) < 10*time.Second; time.Sleep(10 * time.Millisecond) {
+		resp, err := http.Get(ts.URL + "/api/webhooks")
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&got)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(got) != 1 || got[0].StatusCode != http.StatusOK || got[0].Seq != 1 || got[0].EventID != "fake-event" {
+		t.Fatalf("Unexpected deliveries %+v", got)
+	}
+	// The token in the path is not disclosed.
+	if strings.Contains(got[0].URL, "secret-token") || !strings.HasPrefix(got[0].URL, target.URL) {
+		t.Errorf("Unexpected URL %q", got[0].URL)
+	}
+}